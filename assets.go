@@ -4,3 +4,6 @@ import _ "embed"
 
 //go:embed build/windows/icon.ico
 var trayIconICO []byte
+
+//go:embed build/appicon.png
+var appIconPNG []byte
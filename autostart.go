@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goRuntime "runtime"
+	"strings"
+)
+
+// autostartName names the login entry on every platform.
+const autostartName = "bugDog"
+
+// autostartCommand is the command line the session runs at login: this
+// executable, straight to the tray, with the same data location flags.
+func (opts launchOptions) autostartCommand() ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	args := []string{exe, "--minimized"}
+	if opts.dataDir != "" {
+		dir, err := filepath.Abs(opts.dataDir)
+		if err != nil {
+			return nil, fmt.Errorf("data dir: %w", err)
+		}
+		args = append(args, "--data-dir", dir)
+	}
+	if opts.configPath != "" {
+		path, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			return nil, fmt.Errorf("config path: %w", err)
+		}
+		args = append(args, "--config", path)
+	}
+	return args, nil
+}
+
+// setAutoStart installs or removes the login entry. Installing again
+// rewrites it, which picks up a moved executable.
+func setAutoStart(enabled bool, command []string) error {
+	switch goRuntime.GOOS {
+	case "windows":
+		return setAutoStartWindows(enabled, command)
+	case "darwin":
+		return setAutoStartFile(enabled, launchAgentPath, launchAgentPlist(command))
+	default:
+		return setAutoStartFile(enabled, xdgAutostartPath, desktopEntry(command))
+	}
+}
+
+func setAutoStartFile(enabled bool, pathFn func() (string, error), content string) error {
+	path, err := pathFn()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove autostart entry: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("autostart dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write autostart entry: %w", err)
+	}
+	return nil
+}
+
+// xdgAutostartPath follows the XDG autostart spec: $XDG_CONFIG_HOME/autostart,
+// defaulting to ~/.config/autostart.
+func xdgAutostartPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("home dir: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "autostart", "bugdog.desktop"), nil
+}
+
+func desktopEntry(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = desktopQuote(arg)
+	}
+	return strings.Join([]string{
+		"[Desktop Entry]",
+		"Type=Application",
+		"Name=" + autostartName,
+		"Comment=禅道监控",
+		"Exec=" + strings.Join(quoted, " "),
+		"Terminal=false",
+		"X-GNOME-Autostart-enabled=true",
+		"",
+	}, "\n")
+}
+
+// desktopQuote quotes one Exec argument as the desktop entry spec requires.
+// The backslashes are doubled twice: once for the quoting rules and once
+// for the string escaping applied to every value.
+func desktopQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`%=") {
+		return arg
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range arg {
+		switch r {
+		case '"', '`', '$':
+			b.WriteString(`\\`)
+		case '\\':
+			b.WriteString(`\\\`)
+		case '%':
+			b.WriteByte('%')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func launchAgentPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("home dir: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", "com.bugdog.app.plist"), nil
+}
+
+func launchAgentPlist(command []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.bugdog.app</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, arg := range command {
+		b.WriteString("\t\t<string>" + xmlEscape(arg) + "</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`)
+	return b.String()
+}
+
+func xmlEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+func setAutoStartWindows(enabled bool, command []string) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// setAutoStartWindows adds or removes the per-user Run entry.
+func setAutoStartWindows(enabled bool, command []string) error {
+	key, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open Run key: %w", err)
+	}
+	defer key.Close()
+	if !enabled {
+		if err := key.DeleteValue(autostartName); err != nil && err != registry.ErrNotExist {
+			return fmt.Errorf("remove autostart entry: %w", err)
+		}
+		return nil
+	}
+	if err := key.SetStringValue(autostartName, windowsCommandLine(command)); err != nil {
+		return fmt.Errorf("write autostart entry: %w", err)
+	}
+	return nil
+}
+
+// windowsCommandLine quotes command for the Run registry key.
+func windowsCommandLine(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goRuntime "runtime"
+	"strings"
+)
+
+var errBrowserNotFound = errors.New("browser not installed")
+
+// openURL opens link with the browser chosen in the settings and falls back
+// to the system default browser when that one cannot be started.
+func (a *App) openURL(link string) error {
+	settings := a.currentSettings()
+	if settings.Browser != browserDefault {
+		err := startBrowser(settings.Browser, settings.BrowserCommand, link)
+		if err == nil {
+			return nil
+		}
+		a.monitor.Log("warn", fmt.Sprintf("Opening link failed (%v), using the default browser", err))
+	}
+	if err := defaultBrowserCommand(link).Run(); err != nil {
+		return fmt.Errorf("无法打开浏览器: %w", err)
+	}
+	return nil
+}
+
+func startBrowser(browser, template, link string) error {
+	var args []string
+	if browser == browserCustom {
+		args = expandBrowserCommand(template, link)
+		if len(args) == 0 {
+			return errors.New("custom browser command is empty")
+		}
+	} else {
+		command, err := findBrowser(browser)
+		if err != nil {
+			return fmt.Errorf("%s: %w", browser, err)
+		}
+		args = append(command, link)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start %s: %w", args[0], err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// defaultBrowserCommand hands link to the desktop's URL handler. These
+// launchers return as soon as the browser has the link, so callers Run them.
+func defaultBrowserCommand(link string) *exec.Cmd {
+	switch goRuntime.GOOS {
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	case "darwin":
+		return exec.Command("open", link)
+	default:
+		return exec.Command("xdg-open", link)
+	}
+}
+
+// findBrowser returns the command that starts browser, before the URL
+// argument, checking the usual install locations for this platform.
+func findBrowser(browser string) ([]string, error) {
+	switch goRuntime.GOOS {
+	case "windows":
+		return findWindowsBrowser(browser)
+	case "darwin":
+		return findMacBrowser(browser)
+	default:
+		return findLinuxBrowser(browser)
+	}
+}
+
+var linuxBrowsers = map[string]struct {
+	binaries []string
+	flatpak  string
+}{
+	browserChrome:   {[]string{"google-chrome", "google-chrome-stable"}, "com.google.Chrome"},
+	browserChromium: {[]string{"chromium", "chromium-browser"}, "org.chromium.Chromium"},
+	browserEdge:     {[]string{"microsoft-edge", "microsoft-edge-stable"}, "com.microsoft.Edge"},
+	browserFirefox:  {[]string{"firefox"}, "org.mozilla.firefox"},
+}
+
+func findLinuxBrowser(browser string) ([]string, error) {
+	entry, ok := linuxBrowsers[browser]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser %q", browser)
+	}
+	for _, name := range entry.binaries {
+		if path, err := exec.LookPath(name); err == nil {
+			return []string{path}, nil
+		}
+	}
+	// Flatpak exports a launcher per app, whether installed system-wide or
+	// per user.
+	exports := []string{"/var/lib/flatpak/exports/bin"}
+	if home, err := os.UserHomeDir(); err == nil {
+		exports = append(exports, filepath.Join(home, ".local", "share", "flatpak", "exports", "bin"))
+	}
+	for _, dir := range exports {
+		if path := filepath.Join(dir, entry.flatpak); fileExists(path) {
+			return []string{path}, nil
+		}
+	}
+	return nil, errBrowserNotFound
+}
+
+var macBrowsers = map[string]string{
+	browserChrome:   "Google Chrome",
+	browserChromium: "Chromium",
+	browserEdge:     "Microsoft Edge",
+	browserFirefox:  "Firefox",
+}
+
+func findMacBrowser(browser string) ([]string, error) {
+	app, ok := macBrowsers[browser]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser %q", browser)
+	}
+	dirs := []string{"/Applications"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	for _, dir := range dirs {
+		if fileExists(filepath.Join(dir, app+".app")) {
+			return []string{"open", "-a", app}, nil
+		}
+	}
+	return nil, errBrowserNotFound
+}
+
+var windowsBrowsers = map[string][]string{
+	browserChrome:   {`Google\Chrome\Application\chrome.exe`},
+	browserChromium: {`Chromium\Application\chrome.exe`},
+	browserEdge:     {`Microsoft\Edge\Application\msedge.exe`},
+	browserFirefox:  {`Mozilla Firefox\firefox.exe`},
+}
+
+func findWindowsBrowser(browser string) ([]string, error) {
+	paths, ok := windowsBrowsers[browser]
+	if !ok {
+		return nil, fmt.Errorf("unknown browser %q", browser)
+	}
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LOCALAPPDATA"} {
+		base := os.Getenv(env)
+		if base == "" {
+			continue
+		}
+		for _, path := range paths {
+			if full := filepath.Join(base, path); fileExists(full) {
+				return []string{full}, nil
+			}
+		}
+	}
+	return nil, errBrowserNotFound
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// expandBrowserCommand splits a custom command template into arguments,
+// honouring double and single quotes, and substitutes {url}. A template
+// without the placeholder gets the URL as its last argument.
+func expandBrowserCommand(template, link string) []string {
+	args := splitCommandLine(template)
+	substituted := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{url}") {
+			args[i] = strings.ReplaceAll(arg, "{url}", link)
+			substituted = true
+		}
+	}
+	if !substituted && len(args) > 0 {
+		args = append(args, link)
+	}
+	return args
+}
+
+func splitCommandLine(line string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"bugDog_V2/monitor"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Exit codes shared by every subcommand so scripts can branch on them.
+const (
+	exitOK         = 0
+	exitFailure    = 1
+	exitUsage      = 2
+	exitNetwork    = 3
+	exitHTTPStatus = 4
+	exitNotify     = 5
+	exitLogin      = 6
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+type cliCommand struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var cliCommands = map[string]cliCommand{
+	"api-token":   {"print the local API token, or the --subscriber token or --webhook secret", runAPITokenCommand},
+	"fetch":       {"scrape the bug list once and print stats or bugs", runFetchCommand},
+	"history":     {"print stored scrape history", runHistoryCommand},
+	"parse":       {"parse a saved bug list HTML page", runParseCommand},
+	"show":        {"bring the running instance's window to the front", runShowCommand},
+	"sync":        {"make the running instance sync now and print its stats", runSyncCommand},
+	"test-notify": {"send a test message through every configured channel and notification hook", runTestNotifyCommand},
+}
+
+var cliCommandOrder = []string{"api-token", "fetch", "history", "parse", "show", "sync", "test-notify"}
+
+// runCLI dispatches a subcommand. ok is false when args do not name one, in
+// which case the caller starts the app normally.
+func runCLI(args []string, stdout, stderr io.Writer) (code int, ok bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	if args[0] == "help" {
+		printCLIUsage(stdout)
+		return exitOK, true
+	}
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return command.run(args[1:], stdout, stderr), true
+}
+
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: bugdog [--headless | --minimized] [--data-dir DIR] [--config FILE]")
+	fmt.Fprintln(w, "       bugdog <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range cliCommandOrder {
+		fmt.Fprintf(w, "  %-12s %s\n", name, cliCommands[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 ok, 1 failure, 2 usage, 3 network error, 4 HTTP error status, 5 notification failed, 6 login required")
+}
+
+// cliFlags holds the flags every subcommand accepts for locating the data.
+type cliFlags struct {
+	launch  launchOptions
+	format  string
+	verbose bool
+}
+
+func newCLIFlagSet(name string, stderr io.Writer, flags *cliFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("bugdog "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&flags.launch.dataDir, "data-dir", "", "directory holding state, history and secrets")
+	fs.StringVar(&flags.launch.configPath, "config", "", "path to config.json")
+	fs.StringVar(&flags.format, "format", formatTable, "output format: json or table")
+	fs.BoolVar(&flags.verbose, "verbose", false, "log progress to stderr")
+	return fs
+}
+
+func parseCLIFlags(fs *flag.FlagSet, flags *cliFlags, args []string) int {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.format != formatJSON && flags.format != formatTable {
+		fmt.Fprintf(fs.Output(), "unknown format %q\n", flags.format)
+		return exitUsage
+	}
+	return -1
+}
+
+// newCLIMonitor loads the persisted config the same way the GUI does,
+// without starting the poller. The data directory is opened read-only, so a
+// command run next to the app never rewrites its files.
+func newCLIMonitor(flags cliFlags, stderr io.Writer, headless bool) (*monitor.Monitor, error) {
+	opts, err := flags.launch.monitorOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.Headless = headless
+	opts.ReadOnly = true
+	if flags.verbose {
+		opts.Logger = log.New(stderr, "", log.LstdFlags)
+	}
+	core := monitor.New(opts)
+	core.Load()
+	return core, nil
+}
+
+func runFetchCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	var pageURL, cookie string
+	var listBugs bool
+	fs := newCLIFlagSet("fetch", stderr, &flags)
+	fs.StringVar(&pageURL, "url", "", "bug list URL (default: configured URL)")
+	fs.StringVar(&cookie, "cookie", "", "cookie header (default: configured cookie)")
+	fs.BoolVar(&listBugs, "bugs", false, "print the bug list instead of stats")
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+
+	core, err := newCLIMonitor(flags, stderr, true)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	cfg := core.CurrentConfig()
+	if pageURL != "" {
+		cfg.URL = pageURL
+	}
+	if cookie != "" {
+		cfg.Cookie = cookie
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	doc, _, err := core.FetchPage(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", monitor.MaskSecrets(err.Error(), cfg))
+		return fetchExitCode(err)
+	}
+	if listBugs {
+		return printBugs(stdout, stderr, flags.format, monitor.ParseBugs(doc, cfg.URL))
+	}
+	stats := monitor.ParseStats(doc)
+	stats.LastUpdated = time.Now()
+	return printStats(stdout, stderr, flags.format, stats)
+}
+
+func fetchExitCode(err error) int {
+	if errors.Is(err, monitor.ErrLoginRequired) {
+		return exitLogin
+	}
+	var statusErr *monitor.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return exitHTTPStatus
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return exitNetwork
+	}
+	return exitFailure
+}
+
+func runParseCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	var pageURL string
+	var listBugs bool
+	fs := newCLIFlagSet("parse", stderr, &flags)
+	fs.StringVar(&pageURL, "url", "", "URL the page was saved from, used to resolve bug links")
+	fs.BoolVar(&listBugs, "bugs", false, "print the bug list instead of stats")
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: bugdog parse [flags] page.html")
+		return exitUsage
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	defer file.Close()
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	if listBugs {
+		return printBugs(stdout, stderr, flags.format, monitor.ParseBugs(doc, pageURL))
+	}
+	return printStats(stdout, stderr, flags.format, monitor.ParseStats(doc))
+}
+
+func runHistoryCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	var since, bucket string
+	fs := newCLIFlagSet("history", stderr, &flags)
+	fs.StringVar(&since, "since", "7d", "how far back to go, e.g. 12h, 7d or 4w")
+	fs.StringVar(&bucket, "bucket", "", "aggregate into hour, day or week buckets")
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	window, err := parseSince(since)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+
+	core, err := newCLIMonitor(flags, stderr, true)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	from := time.Now().Add(-window)
+	if bucket == "" {
+		records, err := core.HistoryRecords(from, time.Time{})
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitFailure
+		}
+		return printHistoryRecords(stdout, stderr, flags.format, records)
+	}
+	points, err := core.GetHistory(from, time.Time{}, bucket)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+	return printHistoryPoints(stdout, stderr, flags.format, points)
+}
+
+// parseSince accepts Go durations plus the d (day) and w (week) suffixes.
+func parseSince(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.ParseFloat(number, 64)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid --since %q", value)
+			}
+			return time.Duration(count * float64(unit)), nil
+		}
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid --since %q", value)
+	}
+	return window, nil
+}
+
+func runTestNotifyCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	fs := newCLIFlagSet("test-notify", stderr, &flags)
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	core, err := newCLIMonitor(flags, stderr, false)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+
+	const title, message = "禅道监控", "测试通知：命令行触发。"
+	var results []notifyResult
+	for _, channel := range core.Notifiers() {
+		results = append(results, newNotifyResult(channel.Name(), channel.Notify(title, message)))
+	}
+	for _, result := range core.TestNotificationHooks(title, message) {
+		results = append(results, newNotifyResult("hook "+result.Name, result.Err))
+	}
+
+	code := exitOK
+	for _, result := range results {
+		if !result.OK {
+			code = exitNotify
+		}
+	}
+	if flags.format == formatJSON {
+		if printCode := printJSON(stdout, stderr, results); printCode != exitOK {
+			return printCode
+		}
+		return code
+	}
+	for _, result := range results {
+		if result.OK {
+			fmt.Fprintf(stdout, "%-10s ok\n", result.Channel)
+		} else {
+			fmt.Fprintf(stdout, "%-10s failed: %s\n", result.Channel, result.Error)
+		}
+	}
+	return code
+}
+
+// notifyResult is one channel's or hook's outcome in test-notify output.
+type notifyResult struct {
+	Channel string `json:"channel"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newNotifyResult(channel string, err error) notifyResult {
+	if err != nil {
+		return notifyResult{Channel: channel, Error: err.Error()}
+	}
+	return notifyResult{Channel: channel, OK: true}
+}
+
+func runAPITokenCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	var webhook, subscriber bool
+	fs := newCLIFlagSet("api-token", stderr, &flags)
+	fs.BoolVar(&webhook, "webhook", false, "print the webhook secret instead")
+	fs.BoolVar(&subscriber, "subscriber", false, "print the read-only token for relay subscribers instead")
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	core, err := newCLIMonitor(flags, stderr, true)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	if webhook && subscriber {
+		fmt.Fprintln(stderr, "--webhook and --subscriber are mutually exclusive")
+		return exitUsage
+	}
+	token := core.GetAPIToken()
+	switch {
+	case webhook:
+		token = core.GetWebhookSecret()
+	case subscriber:
+		token = core.GetSubscriberToken()
+	}
+	if token == "" {
+		fmt.Fprintln(stderr, "That listener is disabled or has not generated a token yet.")
+		return exitFailure
+	}
+	if flags.format == formatJSON {
+		return printJSON(stdout, stderr, map[string]string{"token": token})
+	}
+	fmt.Fprintln(stdout, token)
+	return exitOK
+}
+
+func runShowCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	fs := newCLIFlagSet("show", stderr, &flags)
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	_, code := forwardInstanceCommand(flags, stderr, instanceShow)
+	return code
+}
+
+func runSyncCommand(args []string, stdout, stderr io.Writer) int {
+	var flags cliFlags
+	fs := newCLIFlagSet("sync", stderr, &flags)
+	if code := parseCLIFlags(fs, &flags, args); code >= 0 {
+		return code
+	}
+	data, code := forwardInstanceCommand(flags, stderr, instanceSync)
+	if code != exitOK {
+		return code
+	}
+	var stats monitor.Stats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	return printStats(stdout, stderr, flags.format, stats)
+}
+
+// forwardInstanceCommand passes command to the instance running on the
+// selected data directory.
+func forwardInstanceCommand(flags cliFlags, stderr io.Writer, command string) (json.RawMessage, int) {
+	opts, err := flags.launch.monitorOptions()
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return nil, exitFailure
+	}
+	data, err := sendInstanceCommand(monitor.New(opts).DataDir(), command)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return nil, exitFailure
+	}
+	return data, exitOK
+}
+
+func printJSON(stdout, stderr io.Writer, payload any) int {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(payload); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+func printStats(stdout, stderr io.Writer, format string, stats monitor.Stats) int {
+	if format == formatJSON {
+		return printJSON(stdout, stderr, stats)
+	}
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TOTAL\tCRITICAL\tSEVERE\tMAJOR\tMINOR")
+	fmt.Fprintf(table, "%d\t%d\t%d\t%d\t%d\n", stats.Total, stats.Severity.Critical, stats.Severity.Severe, stats.Severity.Major, stats.Severity.Minor)
+	return flushTable(table, stderr)
+}
+
+func printBugs(stdout, stderr io.Writer, format string, bugs []monitor.Bug) int {
+	if format == formatJSON {
+		return printJSON(stdout, stderr, bugs)
+	}
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tSEVERITY\tPRI\tSTATUS\tASSIGNED\tTITLE")
+	for _, bug := range bugs {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\n", bug.ID, bug.Severity, bug.Priority, bug.Status, bug.AssignedTo, bug.Title)
+	}
+	return flushTable(table, stderr)
+}
+
+func printHistoryRecords(stdout, stderr io.Writer, format string, records []monitor.HistoryRecord) int {
+	if format == formatJSON {
+		return printJSON(stdout, stderr, records)
+	}
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TIME\tSTATUS\tTOTAL\tCRITICAL\tSEVERE\tMAJOR\tMINOR\tDURATION")
+	for _, record := range records {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%dms\n",
+			record.Timestamp.Local().Format("2006-01-02 15:04:05"), record.Status, record.Total,
+			record.Severity.Critical, record.Severity.Severe, record.Severity.Major, record.Severity.Minor,
+			record.DurationMs)
+	}
+	return flushTable(table, stderr)
+}
+
+func printHistoryPoints(stdout, stderr io.Writer, format string, points []monitor.HistoryPoint) int {
+	if format == formatJSON {
+		return printJSON(stdout, stderr, points)
+	}
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "START\tSAMPLES\tFAILURES\tTOTAL\tMIN\tMAX\tCRITICAL\tSEVERE\tMAJOR\tMINOR")
+	for _, point := range points {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n",
+			point.Start.Format("2006-01-02 15:04"), point.Samples, point.Failures, point.Total,
+			point.MinTotal, point.MaxTotal,
+			point.Severity.Critical, point.Severity.Severe, point.Severity.Major, point.Severity.Minor)
+	}
+	return flushTable(table, stderr)
+}
+
+func flushTable(table *tabwriter.Writer, stderr io.Writer) int {
+	if err := table.Flush(); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitFailure
+	}
+	return exitOK
+}
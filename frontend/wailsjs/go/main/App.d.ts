@@ -1,6 +1,7 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
+import {monitor} from '../models';
 
 export function ClearChangeLog():Promise<void>;
 
@@ -8,22 +9,56 @@ export function ClearMonitoringData():Promise<void>;
 
 export function FetchNow():Promise<void>;
 
-export function GetChangeLog():Promise<Array<main.ChangeLogEntry>>;
+export function GetAPIToken():Promise<string>;
 
-export function GetConfig():Promise<main.Config>;
+export function GetBugDetail(arg1:number):Promise<monitor.BugDetail>;
 
-export function GetLogs():Promise<Array<main.LogEntry>>;
+export function GetBugs():Promise<Array<monitor.Bug>>;
+
+export function GetChangeLog():Promise<Array<monitor.ChangeLogEntry>>;
+
+export function GetConfig():Promise<monitor.Config>;
+
+export function GetHealth():Promise<monitor.Health>;
+
+export function GetHistory(arg1:any,arg2:any,arg3:string):Promise<Array<monitor.HistoryPoint>>;
+
+export function GetLogs():Promise<Array<monitor.LogEntry>>;
 
 export function GetMonitoringStatus():Promise<boolean>;
 
-export function GetStats():Promise<main.Stats>;
+export function GetSettings():Promise<main.Settings>;
+
+export function GetStats():Promise<monitor.Stats>;
+
+export function GetSubscriberToken():Promise<string>;
+
+export function GetUnread():Promise<Array<monitor.Bug>>;
+
+export function GetWatchedBugs():Promise<Array<monitor.WatchedBug>>;
+
+export function GetWebhookSecret():Promise<string>;
+
+export function MarkBugSeen(arg1:number):Promise<void>;
+
+export function MarkSeen():Promise<void>;
+
+export function OpenBug(arg1:number):Promise<void>;
+
+export function OpenURL(arg1:string):Promise<void>;
 
 export function OpenURLInChrome(arg1:string):Promise<void>;
 
-export function SaveConfig(arg1:main.Config):Promise<void>;
+export function SaveConfig(arg1:monitor.Config):Promise<void>;
+
+export function SaveSettings(arg1:main.Settings):Promise<void>;
 
 export function StartMonitoring():Promise<void>;
 
 export function StopMonitoring():Promise<void>;
 
 export function TestNotification():Promise<void>;
+
+export function UnwatchBug(arg1:number):Promise<void>;
+
+export function WatchBug(arg1:number):Promise<void>;
@@ -14,6 +14,18 @@ export function FetchNow() {
   return window['go']['main']['App']['FetchNow']();
 }
 
+export function GetAPIToken() {
+  return window['go']['main']['App']['GetAPIToken']();
+}
+
+export function GetBugDetail(arg1) {
+  return window['go']['main']['App']['GetBugDetail'](arg1);
+}
+
+export function GetBugs() {
+  return window['go']['main']['App']['GetBugs']();
+}
+
 export function GetChangeLog() {
   return window['go']['main']['App']['GetChangeLog']();
 }
@@ -22,6 +34,14 @@ export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetHealth() {
+  return window['go']['main']['App']['GetHealth']();
+}
+
+export function GetHistory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetHistory'](arg1, arg2, arg3);
+}
+
 export function GetLogs() {
   return window['go']['main']['App']['GetLogs']();
 }
@@ -30,10 +50,46 @@ export function GetMonitoringStatus() {
   return window['go']['main']['App']['GetMonitoringStatus']();
 }
 
+export function GetSettings() {
+  return window['go']['main']['App']['GetSettings']();
+}
+
 export function GetStats() {
   return window['go']['main']['App']['GetStats']();
 }
 
+export function GetSubscriberToken() {
+  return window['go']['main']['App']['GetSubscriberToken']();
+}
+
+export function GetUnread() {
+  return window['go']['main']['App']['GetUnread']();
+}
+
+export function GetWatchedBugs() {
+  return window['go']['main']['App']['GetWatchedBugs']();
+}
+
+export function GetWebhookSecret() {
+  return window['go']['main']['App']['GetWebhookSecret']();
+}
+
+export function MarkBugSeen(arg1) {
+  return window['go']['main']['App']['MarkBugSeen'](arg1);
+}
+
+export function MarkSeen() {
+  return window['go']['main']['App']['MarkSeen']();
+}
+
+export function OpenBug(arg1) {
+  return window['go']['main']['App']['OpenBug'](arg1);
+}
+
+export function OpenURL(arg1) {
+  return window['go']['main']['App']['OpenURL'](arg1);
+}
+
 export function OpenURLInChrome(arg1) {
   return window['go']['main']['App']['OpenURLInChrome'](arg1);
 }
@@ -42,6 +98,10 @@ export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
 
+export function SaveSettings(arg1) {
+  return window['go']['main']['App']['SaveSettings'](arg1);
+}
+
 export function StartMonitoring() {
   return window['go']['main']['App']['StartMonitoring']();
 }
@@ -53,3 +113,11 @@ export function StopMonitoring() {
 export function TestNotification() {
   return window['go']['main']['App']['TestNotification']();
 }
+
+export function UnwatchBug(arg1) {
+  return window['go']['main']['App']['UnwatchBug'](arg1);
+}
+
+export function WatchBug(arg1) {
+  return window['go']['main']['App']['WatchBug'](arg1);
+}
@@ -1,5 +1,28 @@
 export namespace main {
 	
+	export class Settings {
+	    autoStart: boolean;
+	    browser: string;
+	    browserCommand: string;
+	    badgeMode: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.autoStart = source["autoStart"];
+	        this.browser = source["browser"];
+	        this.browserCommand = source["browserCommand"];
+	        this.badgeMode = source["badgeMode"];
+	    }
+	}
+
+}
+
+export namespace monitor {
+	
 	export class SeverityCounts {
 	    critical: number;
 	    severe: number;
@@ -21,9 +44,12 @@ export namespace main {
 	export class ChangeLogEntry {
 	    // Go type: time
 	    timestamp: any;
+	    kind: string;
 	    total: number;
 	    delta: number;
 	    severity: SeverityCounts;
+	    bugId?: number;
+	    message?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ChangeLogEntry(source);
@@ -32,9 +58,87 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.kind = source["kind"];
 	        this.total = source["total"];
 	        this.delta = source["delta"];
 	        this.severity = this.convertValues(source["severity"], SeverityCounts);
+	        this.bugId = source["bugId"];
+	        this.message = source["message"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExecHook {
+	    name: string;
+	    command: string;
+	    args: string[];
+	    events: string[];
+	    timeoutSeconds: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExecHook(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.command = source["command"];
+	        this.args = source["args"];
+	        this.events = source["events"];
+	        this.timeoutSeconds = source["timeoutSeconds"];
+	    }
+	}
+	export class Target {
+	    name: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Target(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	    }
+	}
+	export class IgnoreRule {
+	    bugId: number;
+	    titlePattern: string;
+	    module: string;
+	    openedBy: string;
+	    note: string;
+	    // Go type: time
+	    expires: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new IgnoreRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bugId = source["bugId"];
+	        this.titlePattern = source["titlePattern"];
+	        this.module = source["module"];
+	        this.openedBy = source["openedBy"];
+	        this.note = source["note"];
+	        this.expires = this.convertValues(source["expires"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -57,6 +161,7 @@ export namespace main {
 	}
 	export class Config {
 	    url: string;
+	    targets: Target[];
 	    cookie: string;
 	    intervalMinutes: number;
 	    enableNotifications: boolean;
@@ -64,6 +169,21 @@ export namespace main {
 	    notifyLevels: Record<string, boolean>;
 	    notifyOnIncrease: boolean;
 	    notifyOnDecrease: boolean;
+	    secretBackend: string;
+	    source: string;
+	    relayUrl: string;
+	    relayToken: string;
+	    apiEnabled: boolean;
+	    apiHost: string;
+	    apiPort: number;
+	    apiToken: string;
+	    subscriberToken: string;
+	    webhookEnabled: boolean;
+	    webhookAddr: string;
+	    webhookSecret: string;
+	    execHooks: ExecHook[];
+	    watchlist: number[];
+	    ignoreRules: IgnoreRule[];
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -72,6 +192,7 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.url = source["url"];
+	        this.targets = this.convertValues(source["targets"], Target);
 	        this.cookie = source["cookie"];
 	        this.intervalMinutes = source["intervalMinutes"];
 	        this.enableNotifications = source["enableNotifications"];
@@ -79,7 +200,40 @@ export namespace main {
 	        this.notifyLevels = source["notifyLevels"];
 	        this.notifyOnIncrease = source["notifyOnIncrease"];
 	        this.notifyOnDecrease = source["notifyOnDecrease"];
+	        this.secretBackend = source["secretBackend"];
+	        this.source = source["source"];
+	        this.relayUrl = source["relayUrl"];
+	        this.relayToken = source["relayToken"];
+	        this.apiEnabled = source["apiEnabled"];
+	        this.apiHost = source["apiHost"];
+	        this.apiPort = source["apiPort"];
+	        this.apiToken = source["apiToken"];
+	        this.subscriberToken = source["subscriberToken"];
+	        this.webhookEnabled = source["webhookEnabled"];
+	        this.webhookAddr = source["webhookAddr"];
+	        this.webhookSecret = source["webhookSecret"];
+	        this.execHooks = this.convertValues(source["execHooks"], ExecHook);
+	        this.watchlist = source["watchlist"];
+	        this.ignoreRules = this.convertValues(source["ignoreRules"], IgnoreRule);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class LogEntry {
 	    // Go type: time
@@ -87,6 +241,7 @@ export namespace main {
 	    level: string;
 	    status: number;
 	    message: string;
+	    output: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new LogEntry(source);
@@ -98,6 +253,7 @@ export namespace main {
 	        this.level = source["level"];
 	        this.status = source["status"];
 	        this.message = source["message"];
+	        this.output = source["output"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -119,9 +275,50 @@ export namespace main {
 		}
 	}
 	
+	export class TargetStats {
+	    name: string;
+	    url: string;
+	    total: number;
+	    severity: SeverityCounts;
+	
+	    static createFrom(source: any = {}) {
+	        return new TargetStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	        this.total = source["total"];
+	        this.severity = this.convertValues(source["severity"], SeverityCounts);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Stats {
 	    total: number;
 	    severity: SeverityCounts;
+	    actionable: number;
+	    actionableSeverity: SeverityCounts;
+	    muted: number;
+	    unread: number;
+	    targets?: TargetStats[];
 	    // Go type: time
 	    lastUpdated: any;
 	
@@ -133,6 +330,11 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.total = source["total"];
 	        this.severity = this.convertValues(source["severity"], SeverityCounts);
+	        this.actionable = source["actionable"];
+	        this.actionableSeverity = this.convertValues(source["actionableSeverity"], SeverityCounts);
+	        this.muted = source["muted"];
+	        this.unread = source["unread"];
+	        this.targets = this.convertValues(source["targets"], TargetStats);
 	        this.lastUpdated = this.convertValues(source["lastUpdated"], null);
 	    }
 	
@@ -154,6 +356,271 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class HistoryPoint {
+	    // Go type: time
+	    start: any;
+	    samples: number;
+	    failures: number;
+	    total: number;
+	    minTotal: number;
+	    maxTotal: number;
+	    severity: SeverityCounts;
+	    avgDurationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryPoint(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = this.convertValues(source["start"], null);
+	        this.samples = source["samples"];
+	        this.failures = source["failures"];
+	        this.total = source["total"];
+	        this.minTotal = source["minTotal"];
+	        this.maxTotal = source["maxTotal"];
+	        this.severity = this.convertValues(source["severity"], SeverityCounts);
+	        this.avgDurationMs = source["avgDurationMs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Health {
+	    status: string;
+	    monitoring: boolean;
+	    // Go type: time
+	    lastAttempt: any;
+	    // Go type: time
+	    lastSuccess: any;
+	    lastError: string;
+	    errorKind: string;
+	    // Go type: time
+	    snoozedUntil: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Health(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.monitoring = source["monitoring"];
+	        this.lastAttempt = this.convertValues(source["lastAttempt"], null);
+	        this.lastSuccess = this.convertValues(source["lastSuccess"], null);
+	        this.lastError = source["lastError"];
+	        this.errorKind = source["errorKind"];
+	        this.snoozedUntil = this.convertValues(source["snoozedUntil"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Bug {
+	    id: number;
+	    title: string;
+	    severity: string;
+	    priority: string;
+	    status: string;
+	    module: string;
+	    openedBy: string;
+	    assignedTo: string;
+	    url: string;
+	    muted?: boolean;
+	    // Go type: time
+	    firstSeen: any;
+	    unread?: boolean;
+	    targets?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Bug(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.severity = source["severity"];
+	        this.priority = source["priority"];
+	        this.status = source["status"];
+	        this.module = source["module"];
+	        this.openedBy = source["openedBy"];
+	        this.assignedTo = source["assignedTo"];
+	        this.url = source["url"];
+	        this.muted = source["muted"];
+	        this.firstSeen = this.convertValues(source["firstSeen"], null);
+	        this.unread = source["unread"];
+	        this.targets = source["targets"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BugAttachment {
+	    name: string;
+	    url: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BugAttachment(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.url = source["url"];
+	    }
+	}
+	export class BugDetail {
+	    id: number;
+	    title: string;
+	    url: string;
+	    status: string;
+	    assignedTo: string;
+	    severity: string;
+	    steps: string;
+	    expected: string;
+	    actual: string;
+	    attachments: BugAttachment[];
+	    history: string[];
+	    comments: string[];
+	    markdown: string;
+	    // Go type: time
+	    fetchedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new BugDetail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.status = source["status"];
+	        this.assignedTo = source["assignedTo"];
+	        this.severity = source["severity"];
+	        this.steps = source["steps"];
+	        this.expected = source["expected"];
+	        this.actual = source["actual"];
+	        this.attachments = this.convertValues(source["attachments"], BugAttachment);
+	        this.history = source["history"];
+	        this.comments = source["comments"];
+	        this.markdown = source["markdown"];
+	        this.fetchedAt = this.convertValues(source["fetchedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WatchedBug {
+	    id: number;
+	    title: string;
+	    url: string;
+	    status: string;
+	    assignedTo: string;
+	    severity: string;
+	    comments: number;
+	    lastComment: string;
+	    // Go type: time
+	    checkedAt: any;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new WatchedBug(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.url = source["url"];
+	        this.status = source["status"];
+	        this.assignedTo = source["assignedTo"];
+	        this.severity = source["severity"];
+	        this.comments = source["comments"];
+	        this.lastComment = source["lastComment"];
+	        this.checkedAt = this.convertValues(source["checkedAt"], null);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bugDog_V2/monitor"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// runHeadless runs the poller, persistence and notifier channels without a
+// window or tray until SIGINT/SIGTERM. It returns the process exit code.
+func runHeadless(opts launchOptions) int {
+	logger := log.New(os.Stdout, "", log.LstdFlags)
+	monitorOpts, err := opts.monitorOptions()
+	if err != nil {
+		logger.Printf("[error] %v", err)
+		return 1
+	}
+	monitorOpts.Headless = true
+	monitorOpts.Logger = logger
+	core := monitor.New(monitorOpts)
+
+	inst, err := acquireInstance(core.DataDir(), func(command string, args []string) (any, error) {
+		if command == instanceSync {
+			return syncForInstance(core)
+		}
+		return nil, fmt.Errorf("command %q is not available in headless mode", command)
+	})
+	if errors.Is(err, errInstanceRunning) {
+		logger.Printf("[error] %v", err)
+		return 1
+	}
+	if err != nil {
+		logger.Printf("[warn] Single-instance lock unavailable: %v", err)
+	} else {
+		defer inst.release()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Printf("[info] Starting headless mode, data in %s", core.DataDir())
+	core.Start(ctx)
+	go core.FetchNow()
+
+	<-ctx.Done()
+	logger.Printf("[info] Shutting down")
+	if !core.Shutdown(shutdownTimeout) {
+		logger.Printf("[warn] Sync still running at shutdown, exiting anyway")
+	}
+	return 0
+}
+
+// syncForInstance runs a sync for a forwarded sync command and returns the
+// resulting stats.
+func syncForInstance(core *monitor.Monitor) (any, error) {
+	if err := core.FetchNow(); err != nil {
+		return nil, errors.New(monitor.MaskSecrets(err.Error(), core.CurrentConfig()))
+	}
+	return core.GetStats(), nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	instanceLockName = "instance.lock"
+	instanceInfoName = "instance.json"
+
+	instanceRequestTimeout = 5 * time.Second
+	instanceReplyTimeout   = time.Minute
+	instanceRetries        = 10
+	instanceRetryDelay     = 200 * time.Millisecond
+)
+
+// Commands a second launch can pass to the running instance.
+const (
+	instanceShow = "show"
+	instanceSync = "sync"
+)
+
+var (
+	errInstanceRunning    = errors.New("another bugDog instance is using this data directory")
+	errInstanceNotRunning = errors.New("bugDog is not running for this data directory")
+)
+
+// instanceInfo is written next to the lock so later launches can reach the
+// running instance.
+type instanceInfo struct {
+	PID   int    `json:"pid"`
+	Addr  string `json:"addr"`
+	Token string `json:"token"`
+}
+
+type instanceRequest struct {
+	Token   string   `json:"token"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+type instanceReply struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// instanceHandler runs one forwarded command and returns its result, which
+// is sent back as JSON.
+type instanceHandler func(command string, args []string) (any, error)
+
+// instance holds the data directory lock and serves the IPC channel for as
+// long as this process runs.
+type instance struct {
+	lock     *os.File
+	listener net.Listener
+	infoPath string
+	token    string
+	handler  instanceHandler
+}
+
+// acquireInstance locks dataDir for this process. It returns
+// errInstanceRunning when another process holds the lock; the OS drops the
+// lock when that process exits, however it exits.
+func acquireInstance(dataDir string, handler instanceHandler) (*instance, error) {
+	lock, err := os.OpenFile(filepath.Join(dataDir, instanceLockName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open instance lock: %w", err)
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		unlockFile(lock)
+		lock.Close()
+		return nil, fmt.Errorf("instance listener: %w", err)
+	}
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		listener.Close()
+		unlockFile(lock)
+		lock.Close()
+		return nil, fmt.Errorf("instance token: %w", err)
+	}
+	inst := &instance{
+		lock:     lock,
+		listener: listener,
+		infoPath: filepath.Join(dataDir, instanceInfoName),
+		token:    hex.EncodeToString(buf),
+		handler:  handler,
+	}
+	info := instanceInfo{PID: os.Getpid(), Addr: listener.Addr().String(), Token: inst.token}
+	data, _ := json.MarshalIndent(info, "", "  ")
+	if err := os.WriteFile(inst.infoPath, data, 0o600); err != nil {
+		inst.release()
+		return nil, fmt.Errorf("write instance info: %w", err)
+	}
+	go inst.serve()
+	return inst, nil
+}
+
+// release stops the IPC channel and drops the lock. The lock file itself
+// stays, since deleting it would race with a launch that just opened it.
+func (inst *instance) release() {
+	inst.listener.Close()
+	_ = os.Remove(inst.infoPath)
+	unlockFile(inst.lock)
+	inst.lock.Close()
+}
+
+func (inst *instance) serve() {
+	for {
+		conn, err := inst.listener.Accept()
+		if err != nil {
+			return
+		}
+		go inst.handle(conn)
+	}
+}
+
+func (inst *instance) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(instanceRequestTimeout))
+	var req instanceRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(inst.token)) != 1 {
+		writeInstanceReply(conn, instanceReply{Error: "invalid token"})
+		return
+	}
+	result, err := inst.handler(req.Command, req.Args)
+	if err != nil {
+		writeInstanceReply(conn, instanceReply{Error: err.Error()})
+		return
+	}
+	reply := instanceReply{OK: true}
+	if result != nil {
+		reply.Data, _ = json.Marshal(result)
+	}
+	writeInstanceReply(conn, reply)
+}
+
+func writeInstanceReply(conn net.Conn, reply instanceReply) {
+	_ = conn.SetWriteDeadline(time.Now().Add(instanceRequestTimeout))
+	_ = json.NewEncoder(conn).Encode(reply)
+}
+
+// sendInstanceCommand passes command to the instance running on dataDir
+// and returns its result. It retries briefly, since a freshly started
+// instance may hold the lock before it has written its address.
+func sendInstanceCommand(dataDir, command string, args ...string) (json.RawMessage, error) {
+	var lastErr error
+	for attempt := 0; attempt < instanceRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(instanceRetryDelay)
+		}
+		info, err := readInstanceInfo(dataDir)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", info.Addr, instanceRequestTimeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		reply, err := exchangeInstance(conn, instanceRequest{Token: info.Token, Command: command, Args: args})
+		if err != nil {
+			return nil, err
+		}
+		if !reply.OK {
+			return nil, errors.New(reply.Error)
+		}
+		return reply.Data, nil
+	}
+	if errors.Is(lastErr, os.ErrNotExist) || !instanceLocked(dataDir) {
+		return nil, errInstanceNotRunning
+	}
+	return nil, fmt.Errorf("reach running instance: %w", lastErr)
+}
+
+func exchangeInstance(conn net.Conn, req instanceRequest) (instanceReply, error) {
+	defer conn.Close()
+	var reply instanceReply
+	_ = conn.SetDeadline(time.Now().Add(instanceReplyTimeout))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return reply, fmt.Errorf("send to running instance: %w", err)
+	}
+	if err := json.NewDecoder(conn).Decode(&reply); err != nil {
+		return reply, fmt.Errorf("read from running instance: %w", err)
+	}
+	return reply, nil
+}
+
+// instanceLocked reports whether some process holds the lock on dataDir,
+// which tells a crashed instance's leftover info file from a live one.
+func instanceLocked(dataDir string) bool {
+	lock, err := os.OpenFile(filepath.Join(dataDir, instanceLockName), os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return errors.Is(err, errInstanceRunning)
+	}
+	unlockFile(lock)
+	return false
+}
+
+func readInstanceInfo(dataDir string) (instanceInfo, error) {
+	var info instanceInfo
+	data, err := os.ReadFile(filepath.Join(dataDir, instanceInfoName))
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("instance info: %w", err)
+	}
+	return info, nil
+}
@@ -2,6 +2,14 @@ package main
 
 import (
 	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"bugDog_V2/monitor"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
@@ -11,18 +19,101 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+type launchOptions struct {
+	headless   bool
+	minimized  bool
+	dataDir    string
+	configPath string
+}
+
+func parseLaunchOptions(args []string) (launchOptions, error) {
+	var opts launchOptions
+	fs := flag.NewFlagSet("bugdog", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.headless, "headless", false, "run the poller without a window or tray")
+	fs.BoolVar(&opts.minimized, "minimized", false, "start hidden in the tray")
+	fs.StringVar(&opts.dataDir, "data-dir", "", "directory for state, history and secrets")
+	fs.StringVar(&opts.configPath, "config", "", "path to config.json")
+	err := fs.Parse(args)
+	return opts, err
+}
+
+// monitorOptions turns the data location flags into monitor options.
+func (opts launchOptions) monitorOptions() (monitor.Options, error) {
+	var monitorOpts monitor.Options
+	if opts.dataDir != "" {
+		if err := os.MkdirAll(opts.dataDir, 0o755); err != nil {
+			return monitorOpts, fmt.Errorf("data dir: %w", err)
+		}
+		monitorOpts.DataDir = opts.dataDir
+	}
+	if opts.configPath != "" {
+		path, err := filepath.Abs(opts.configPath)
+		if err != nil {
+			return monitorOpts, fmt.Errorf("config path: %w", err)
+		}
+		monitorOpts.ConfigPath = path
+	}
+	return monitorOpts, nil
+}
+
 func main() {
+	if code, ok := runCLI(os.Args[1:], os.Stdout, os.Stderr); ok {
+		os.Exit(code)
+	}
+
+	opts, err := parseLaunchOptions(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		printCLIUsage(os.Stdout)
+		os.Exit(exitOK)
+	}
+	if opts.headless {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(2)
+		}
+		os.Exit(runHeadless(opts))
+	}
+	if err != nil {
+		// Unknown flags may belong to the Wails dev runtime; leave them to it.
+		opts = launchOptions{}
+	}
+
+	monitorOpts, err := opts.monitorOptions()
+	if err != nil {
+		println("Error:", err.Error())
+		return
+	}
+
 	// Create an instance of the app structure
-	app := NewApp()
+	app := NewApp(opts, monitorOpts)
+
+	inst, err := acquireInstance(app.monitor.DataDir(), app.handleInstanceCommand)
+	if errors.Is(err, errInstanceRunning) {
+		// Autostart launching a second copy should stay quiet; anything
+		// else brings the running window forward.
+		if !opts.minimized {
+			if _, err := sendInstanceCommand(app.monitor.DataDir(), instanceShow); err != nil {
+				println("Error:", err.Error())
+			}
+		}
+		return
+	}
+	if err != nil {
+		println("Warning: single-instance lock unavailable:", err.Error())
+	} else {
+		defer inst.release()
+	}
 
 	// Create application with options
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "禅道监控",
 		Width:  1024,
 		Height: 768,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
+		StartHidden:      opts.minimized,
 		BackgroundColour: &options.RGBA{R: 240, G: 242, B: 245, A: 1},
 		OnStartup:        app.startup,
 		OnShutdown:       app.shutdown,
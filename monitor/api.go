@@ -0,0 +1,211 @@
+package monitor
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetAPIToken returns the token scripts authenticate with. GetConfig masks
+// it like any other secret, so the settings page reveals it through this.
+func (m *Monitor) GetAPIToken() string {
+	return m.currentConfig().APIToken
+}
+
+// GetSubscriberToken returns the read-only token handed to relay
+// subscribers. It only reaches the routes in subscriberRoutes.
+func (m *Monitor) GetSubscriberToken() string {
+	return m.currentConfig().SubscriberToken
+}
+
+// subscriberRoutes are the route patterns the subscriber token may use:
+// what a relay subscriber needs to follow the counts and read bug details,
+// and nothing that changes state. Bug details come from the cache, so a
+// subscriber cannot make the relay scrape ZenTao more than once per
+// detailCacheTTL per bug.
+var subscriberRoutes = map[string]bool{
+	"GET /stats":     true,
+	"GET /bugs":      true,
+	"GET /bugs/{id}": true,
+	"GET /events":    true,
+}
+
+// subscriberEvents are the events streamed to subscriber connections. The
+// config and logs would reveal hook commands, targets and the like.
+var subscriberEvents = map[string]bool{
+	EventStats:     true,
+	EventWatchlist: true,
+}
+
+// subscriberKey marks a request made with the subscriber token.
+type subscriberKey struct{}
+
+func isSubscriber(r *http.Request) bool {
+	return r.Context().Value(subscriberKey{}) != nil
+}
+
+// startAPI (re)starts the API server to match the current config, or stops
+// it when the API is disabled.
+func (m *Monitor) startAPI() {
+	m.stopAPI()
+	cfg := m.currentConfig()
+	if !cfg.APIEnabled {
+		return
+	}
+	if err := m.ensureToken("API token", func(c *Config) *string { return &c.APIToken }); err != nil {
+		return
+	}
+	if err := m.ensureToken("subscriber token", func(c *Config) *string { return &c.SubscriberToken }); err != nil {
+		return
+	}
+	addr := net.JoinHostPort(cfg.APIHost, strconv.Itoa(cfg.APIPort))
+	server, err := m.listenHTTP("API server", addr, m.apiHandler())
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	m.api = server
+	m.mu.Unlock()
+}
+
+func (m *Monitor) stopAPI() {
+	m.mu.Lock()
+	server := m.api
+	m.api = nil
+	m.mu.Unlock()
+	m.closeHTTP(server)
+}
+
+// apiHandler serves the REST API, on 127.0.0.1 unless APIHost says
+// otherwise. Every request must carry the API token, or for the read-only
+// routes the subscriber token, as "Authorization: Bearer <token>",
+// "X-BugDog-Token" or, for EventSource clients that cannot set headers,
+// ?token=.
+func (m *Monitor) apiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetHealth())
+	})
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetStats())
+	})
+	mux.HandleFunc("GET /bugs", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetBugs())
+	})
+	mux.HandleFunc("GET /bugs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id < 1 {
+			writeAPIError(w, http.StatusBadRequest, "invalid bug id")
+			return
+		}
+		lookup := m.bugDetail
+		if fresh, _ := strconv.ParseBool(r.URL.Query().Get("fresh")); fresh && !isSubscriber(r) {
+			lookup = m.refreshBugDetail
+		}
+		detail, err := lookup(r.Context(), id)
+		if err != nil {
+			writeAPIError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, detail)
+	})
+	mux.HandleFunc("GET /unread", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetUnread())
+	})
+	mux.HandleFunc("POST /unread/seen", func(w http.ResponseWriter, r *http.Request) {
+		if err := m.MarkSeen(); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAPIJSON(w, http.StatusOK, m.GetStats())
+	})
+	mux.HandleFunc("GET /watchlist", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetWatchedBugs())
+	})
+	mux.HandleFunc("GET /changelog", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetChangeLog())
+	})
+	mux.HandleFunc("GET /logs", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIJSON(w, http.StatusOK, m.GetLogs())
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.writeMetrics(w)
+	})
+	mux.HandleFunc("GET /events", m.handleAPIEvents)
+	mux.HandleFunc("POST /sync", m.handleAPISync)
+	mux.HandleFunc("POST /monitoring/start", func(w http.ResponseWriter, r *http.Request) {
+		m.SetMonitoring(true)
+		writeAPIJSON(w, http.StatusOK, m.GetHealth())
+	})
+	mux.HandleFunc("POST /monitoring/stop", func(w http.ResponseWriter, r *http.Request) {
+		m.SetMonitoring(false)
+		writeAPIJSON(w, http.StatusOK, m.GetHealth())
+	})
+	return m.requireAPIToken(mux)
+}
+
+// handleAPISync queues a sync and answers 202 straight away. With ?wait=1 it
+// holds the response until the sync finishes and reports its outcome.
+func (m *Monitor) handleAPISync(w http.ResponseWriter, r *http.Request) {
+	run := m.requestSync()
+	if wait, _ := strconv.ParseBool(r.URL.Query().Get("wait")); !wait {
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+		return
+	}
+	if err := run.wait(); err != nil {
+		writeAPIError(w, http.StatusBadGateway, MaskSecrets(err.Error(), m.currentConfig()))
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, m.GetStats())
+}
+
+func (m *Monitor) requireAPIToken(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := m.currentConfig()
+		given := r.Header.Get("X-BugDog-Token")
+		if given == "" {
+			given = r.URL.Query().Get("token")
+		}
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			given = strings.TrimSpace(bearer)
+		}
+		switch {
+		case tokenMatches(given, cfg.APIToken):
+		case tokenMatches(given, cfg.SubscriberToken):
+			if _, pattern := mux.Handler(r); !subscriberRoutes[pattern] {
+				writeAPIError(w, http.StatusForbidden, "the subscriber token is read-only")
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), subscriberKey{}, true))
+		default:
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing API token")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func tokenMatches(given, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIJSON(w, status, map[string]string{"error": message})
+}
+
+func apiConfigChanged(previous, cfg Config) bool {
+	return previous.APIEnabled != cfg.APIEnabled ||
+		previous.APIHost != cfg.APIHost ||
+		previous.APIPort != cfg.APIPort
+}
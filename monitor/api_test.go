@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPITokenScopes(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	m.config.APIToken = "full-token"
+	m.config.SubscriberToken = "read-token"
+	// A subscriber's fresh=1 must be answered from the cache rather than by
+	// scraping, so the cached detail is all there is to serve.
+	m.detailCache = map[int]cachedBugDetail{7: {detail: BugDetail{ID: 7, Title: "缓存"}, expires: time.Now().Add(time.Hour)}}
+	handler := m.apiHandler()
+
+	tests := []struct {
+		method, target, token string
+		want                  int
+	}{
+		{http.MethodGet, "/stats", "full-token", http.StatusOK},
+		{http.MethodGet, "/stats", "read-token", http.StatusOK},
+		{http.MethodGet, "/bugs", "read-token", http.StatusOK},
+		{http.MethodGet, "/health", "read-token", http.StatusForbidden},
+		{http.MethodGet, "/bugs/7?fresh=1", "read-token", http.StatusOK},
+		{http.MethodGet, "/watchlist", "read-token", http.StatusForbidden},
+		{http.MethodGet, "/logs", "read-token", http.StatusForbidden},
+		{http.MethodPost, "/sync", "read-token", http.StatusForbidden},
+		{http.MethodPost, "/monitoring/stop", "read-token", http.StatusForbidden},
+		{http.MethodGet, "/stats", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/stats", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s with %q = %d, want %d", tt.method, tt.target, tt.token, rec.Code, tt.want)
+		}
+	}
+	if !m.isMonitoringEnabled() {
+		t.Error("the subscriber token stopped monitoring")
+	}
+}
+
+// streamEventNames connects to /events with token and returns the names of
+// the events received until the stats event that follows emitting.
+func streamEventNames(t *testing.T, m *Monitor, server *httptest.Server, token string) []string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/events with %q = %d", token, resp.StatusCode)
+	}
+
+	var names []string
+	stats := 0
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		name, ok := strings.CutPrefix(scanner.Text(), "event: ")
+		if !ok {
+			continue
+		}
+		names = append(names, name)
+		if name != EventStats {
+			continue
+		}
+		stats++
+		if stats == 1 {
+			// The snapshot is out, so the subscription is live.
+			m.emitConfig()
+			m.Log("info", "hook output")
+			m.emitStats()
+		} else {
+			return names
+		}
+	}
+	t.Fatalf("stream ended after %v: %v", names, scanner.Err())
+	return nil
+}
+
+func TestSubscriberEventStream(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	m.config.APIToken = "full-token"
+	m.config.SubscriberToken = "read-token"
+	server := httptest.NewServer(m.apiHandler())
+	t.Cleanup(server.Close)
+
+	for _, name := range streamEventNames(t, m, server, "read-token") {
+		if !subscriberEvents[name] {
+			t.Errorf("subscriber received a %q event", name)
+		}
+	}
+	full := strings.Join(streamEventNames(t, m, server, "full-token"), " ")
+	if !strings.Contains(full, EventConfig) || !strings.Contains(full, EventLogs) {
+		t.Errorf("full token events = %s, want config and logs too", full)
+	}
+}
+
+func TestWebhookRejectsAreRateLimited(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	m.config.WebhookSecret = "hook-secret"
+	handler := m.webhookHandler()
+	post := func(remote, target, body string) int {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	rejected := func() []string {
+		var messages []string
+		for _, entry := range m.GetLogs() {
+			if strings.Contains(entry.Message, "Webhook from") {
+				messages = append(messages, entry.Message)
+			}
+		}
+		return messages
+	}
+
+	for port := 1000; port < 1010; port++ {
+		if code := post(fmt.Sprintf("10.0.0.5:%d", port), "/zentao?token=wrong", "{}"); code != http.StatusUnauthorized {
+			t.Fatalf("bad token = %d, want 401", code)
+		}
+	}
+	if code := post("10.0.0.5:2000", "/zentao?token=hook-secret", "not json"); code != http.StatusBadRequest {
+		t.Fatalf("malformed body = %d, want 400", code)
+	}
+	if code := post("10.0.0.6:1000", "/zentao?token=wrong", "{}"); code != http.StatusUnauthorized {
+		t.Fatalf("bad token = %d, want 401", code)
+	}
+	if got := rejected(); len(got) != 2 {
+		t.Fatalf("rejection log entries = %q, want one per remote host", got)
+	}
+
+	m.mu.Lock()
+	m.webhookRejects["10.0.0.5"].logged = time.Now().Add(-webhookRejectLogEvery)
+	m.mu.Unlock()
+	post("10.0.0.5:3000", "/zentao", "{}")
+	got := rejected()
+	if len(got) != 3 || !strings.Contains(got[0], "10 more rejected") {
+		t.Errorf("rejection log entries = %q, want a third reporting the 10 dropped", got)
+	}
+}
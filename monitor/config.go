@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultURL = "https://zentao.sskuaixiu.com/my-work-bug.html?tid=r6xl1evk"
+
+const (
+	defaultAPIHost = "127.0.0.1"
+	defaultAPIPort = 47831
+)
+
+func defaultNotifyLevels() map[string]bool {
+	return map[string]bool{
+		"level1": true,
+		"level2": true,
+		"level3": true,
+		"level4": true,
+	}
+}
+
+func defaultConfig() Config {
+	return Config{
+		URL:                 defaultURL,
+		IntervalMinutes:     15,
+		EnableNotifications: true,
+		EnableSound:         true,
+		NotifyLevels:        defaultNotifyLevels(),
+		NotifyOnIncrease:    true,
+		NotifyOnDecrease:    true,
+		SecretBackend:       secretBackendFile,
+		Source:              sourceScrape,
+		APIHost:             defaultAPIHost,
+		APIPort:             defaultAPIPort,
+		WebhookAddr:         defaultWebhookAddr,
+	}
+}
+
+func sanitizeConfig(cfg Config) Config {
+	cfg.URL = strings.TrimSpace(cfg.URL)
+	cfg.Cookie = strings.TrimSpace(cfg.Cookie)
+	if cfg.URL == "" {
+		cfg.URL = defaultURL
+	}
+	if cfg.IntervalMinutes < 1 {
+		cfg.IntervalMinutes = 1
+	}
+	if cfg.IntervalMinutes > 60 {
+		cfg.IntervalMinutes = 60
+	}
+	cfg.NotifyLevels = sanitizeNotifyLevels(cfg.NotifyLevels)
+	if !cfg.NotifyOnIncrease && !cfg.NotifyOnDecrease {
+		cfg.NotifyOnIncrease = true
+		cfg.NotifyOnDecrease = true
+	}
+	if cfg.SecretBackend != secretBackendKeyring {
+		cfg.SecretBackend = secretBackendFile
+	}
+	if cfg.Source != sourceRelay {
+		cfg.Source = sourceScrape
+	}
+	cfg.RelayURL = strings.TrimRight(strings.TrimSpace(cfg.RelayURL), "/")
+	cfg.RelayToken = strings.TrimSpace(cfg.RelayToken)
+	cfg.APIHost = strings.TrimSpace(cfg.APIHost)
+	if cfg.APIHost == "" {
+		cfg.APIHost = defaultAPIHost
+	}
+	if cfg.APIPort < 1024 || cfg.APIPort > 65535 {
+		cfg.APIPort = defaultAPIPort
+	}
+	cfg.APIToken = strings.TrimSpace(cfg.APIToken)
+	cfg.SubscriberToken = strings.TrimSpace(cfg.SubscriberToken)
+	cfg.WebhookAddr = strings.TrimSpace(cfg.WebhookAddr)
+	if cfg.WebhookAddr == "" {
+		cfg.WebhookAddr = defaultWebhookAddr
+	}
+	cfg.WebhookSecret = strings.TrimSpace(cfg.WebhookSecret)
+	cfg.ExecHooks = sanitizeExecHooks(cfg.ExecHooks)
+	cfg.Targets = sanitizeTargets(cfg.Targets, cfg.URL)
+	cfg.Watchlist = sanitizeWatchlist(cfg.Watchlist)
+	cfg.IgnoreRules = sanitizeIgnoreRules(cfg.IgnoreRules)
+	return cfg
+}
+
+func sanitizeNotifyLevels(levels map[string]bool) map[string]bool {
+	if levels == nil || len(levels) == 0 {
+		return defaultNotifyLevels()
+	}
+	defaults := defaultNotifyLevels()
+	for key, value := range defaults {
+		if _, ok := levels[key]; !ok {
+			levels[key] = value
+		}
+	}
+	return levels
+}
+
+func (m *Monitor) loadConfig() error {
+	cfg := defaultConfig()
+	err := m.readVersioned(configSchema, &cfg)
+	if err != nil {
+		cfg = defaultConfig()
+	}
+	cfg = sanitizeConfig(cfg)
+	store := m.openSecretStore(cfg.SecretBackend)
+	if m.loadSecrets(store, &cfg) && err == nil {
+		// The backup still holds the plain-text values, so drop it once the
+		// stripped config is safely on disk.
+		if err := m.writeVersioned(configSchema, stripSecrets(cfg)); err == nil {
+			_ = os.Remove(m.dataPath(configFileName) + backupSuffix)
+		}
+	}
+	m.mu.Lock()
+	m.config = cfg
+	m.secrets = store
+	m.mu.Unlock()
+	return err
+}
+
+// saveConfig writes secrets to the secret store and everything else to
+// config.json.
+func (m *Monitor) saveConfig(cfg Config) error {
+	if m.readOnly {
+		return fmt.Errorf("not saving %s: %w", configFileName, errReadOnly)
+	}
+	m.mu.Lock()
+	store := m.secrets
+	m.mu.Unlock()
+	if store != nil {
+		if err := m.saveSecrets(store, cfg); err != nil {
+			return err
+		}
+	}
+	return m.writeVersioned(configSchema, stripSecrets(cfg))
+}
+
+func (m *Monitor) loadState() error {
+	var state State
+	if err := m.readVersioned(stateSchema, &state); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.stats = state.LastStats
+	m.bugs = state.LastBugs
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Monitor) saveState(stats Stats, bugs []Bug) error {
+	return m.writeVersioned(stateSchema, State{LastStats: stats, LastBugs: bugs})
+}
+
+type changeLogFile struct {
+	Entries []ChangeLogEntry `json:"entries"`
+}
+
+func (m *Monitor) loadChangeLog() error {
+	var file changeLogFile
+	if err := m.readVersioned(changeLogSchema, &file); err != nil {
+		return err
+	}
+	m.changeLog = file.Entries
+	return nil
+}
+
+func (m *Monitor) saveChangeLog(entries []ChangeLogEntry) error {
+	if entries == nil {
+		entries = []ChangeLogEntry{}
+	}
+	return m.writeVersioned(changeLogSchema, changeLogFile{Entries: entries})
+}
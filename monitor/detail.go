@@ -0,0 +1,498 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+const (
+	detailCacheTTL     = 10 * time.Minute
+	detailFetchTimeout = 10 * time.Second
+	previewBugs        = 3
+	previewLines       = 2
+	previewLineRunes   = 60
+)
+
+// BugAttachment is one file attached to a bug.
+type BugAttachment struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// BugDetail is the readable content of a bug-view page. Steps, Expected and
+// Actual are plain text split out of ZenTao's steps field; Markdown renders
+// the whole page for display.
+type BugDetail struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	URL         string          `json:"url"`
+	Status      string          `json:"status"`
+	AssignedTo  string          `json:"assignedTo"`
+	Severity    string          `json:"severity"`
+	Steps       string          `json:"steps"`
+	Expected    string          `json:"expected"`
+	Actual      string          `json:"actual"`
+	Attachments []BugAttachment `json:"attachments"`
+	History     []string        `json:"history"`
+	Comments    []string        `json:"comments"`
+	Markdown    string          `json:"markdown"`
+	FetchedAt   time.Time       `json:"fetchedAt"`
+}
+
+type cachedBugDetail struct {
+	detail  BugDetail
+	expires time.Time
+}
+
+// GetBugDetail returns the detail of bug id, from the ZenTao bug-view page
+// with the configured cookie, or from the relay in relay mode. Results are
+// cached for detailCacheTTL.
+func (m *Monitor) GetBugDetail(id int) (BugDetail, error) {
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.bugDetail(ctx, id)
+}
+
+func (m *Monitor) bugDetail(ctx context.Context, id int) (BugDetail, error) {
+	m.mu.Lock()
+	cached, ok := m.detailCache[id]
+	m.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.detail, nil
+	}
+	return m.refreshBugDetail(ctx, id)
+}
+
+// refreshBugDetail fetches bug id past the cache and caches the result.
+func (m *Monitor) refreshBugDetail(ctx context.Context, id int) (BugDetail, error) {
+	cfg := m.currentConfig()
+	var detail BugDetail
+	var err error
+	if cfg.Source == sourceRelay {
+		_, err = m.getRelayJSON(ctx, cfg, "/bugs/"+strconv.Itoa(id)+"?fresh=1", &detail)
+	} else {
+		detail, err = m.fetchBugDetail(ctx, cfg, id)
+	}
+	if err != nil {
+		return BugDetail{}, errors.New(MaskSecrets(err.Error(), cfg))
+	}
+
+	now := time.Now()
+	m.mu.Lock()
+	if m.detailCache == nil {
+		m.detailCache = map[int]cachedBugDetail{}
+	}
+	for key, entry := range m.detailCache {
+		if now.After(entry.expires) {
+			delete(m.detailCache, key)
+		}
+	}
+	m.detailCache[id] = cachedBugDetail{detail: detail, expires: now.Add(detailCacheTTL)}
+	m.mu.Unlock()
+	return detail, nil
+}
+
+// detailSourceChanged reports whether cfg reads bug details from somewhere
+// other than previous did, which makes the cached ones stale.
+func detailSourceChanged(previous, cfg Config) bool {
+	return previous.URL != cfg.URL ||
+		previous.Source != cfg.Source ||
+		previous.RelayURL != cfg.RelayURL ||
+		!slices.Equal(previous.Targets, cfg.Targets)
+}
+
+func (m *Monitor) clearDetailCache() {
+	m.mu.Lock()
+	m.detailCache = nil
+	m.mu.Unlock()
+}
+
+func (m *Monitor) fetchBugDetail(ctx context.Context, cfg Config, id int) (BugDetail, error) {
+	viewURL := bugViewURL(cfg.URL, id)
+	if viewURL == "" {
+		return BugDetail{}, fmt.Errorf("cannot build a bug-view URL from %q", cfg.URL)
+	}
+	pageCfg := cfg
+	pageCfg.URL = viewURL
+	doc, _, err := m.FetchPage(ctx, pageCfg)
+	if err != nil {
+		return BugDetail{}, err
+	}
+	detail := ParseBugDetail(doc, viewURL)
+	detail.ID = id
+	detail.FetchedAt = time.Now()
+	return detail, nil
+}
+
+var (
+	pageTitleSuffix = regexp.MustCompile(`\s*[-–]\s*禅道.*$`)
+	bugTitlePrefix  = regexp.MustCompile(`^(?:BUG|Bug|bug)?\s*#?\d+\s*`)
+)
+
+// ParseBugDetail extracts a bug-view page. Sections are found by their
+// heading text, which holds across ZenTao's themes and versions better
+// than their markup does.
+func ParseBugDetail(doc *goquery.Document, pageURL string) BugDetail {
+	base, _ := url.Parse(pageURL)
+	detail := BugDetail{URL: pageURL, Attachments: []BugAttachment{}, History: []string{}, Comments: []string{}}
+
+	detail.Title = strings.TrimSpace(doc.Find(".page-title .text").First().AttrOr("title", ""))
+	if detail.Title == "" {
+		detail.Title = collapseSpace(doc.Find(".page-title .text").First().Text())
+	}
+	if detail.Title == "" {
+		title := pageTitleSuffix.ReplaceAllString(collapseSpace(doc.Find("title").Text()), "")
+		detail.Title = strings.TrimSpace(bugTitlePrefix.ReplaceAllString(title, ""))
+	}
+
+	doc.Find(".detail").Each(func(_ int, section *goquery.Selection) {
+		heading := collapseSpace(section.Find(".detail-title").First().Text())
+		content := section.Find(".detail-content").First()
+		switch {
+		case containsAny(heading, "重现步骤", "Steps"):
+			text := htmlToText(content, base)
+			detail.Steps, detail.Actual, detail.Expected = splitStepSections(text)
+		case containsAny(heading, "附件", "Files", "Attachments"):
+			detail.Attachments = append(detail.Attachments, parseAttachments(section, base)...)
+		case containsAny(heading, "历史记录", "History"):
+			detail.History = append(detail.History, parseHistory(section)...)
+		}
+	})
+	if len(detail.Attachments) == 0 {
+		detail.Attachments = append(detail.Attachments, parseAttachments(doc.Find(".files-list"), base)...)
+	}
+	if len(detail.History) == 0 {
+		detail.History = append(detail.History, parseHistory(doc.Find("#actionbox, .histories"))...)
+	}
+	doc.Find(".histories-list .comment").Each(func(_ int, comment *goquery.Selection) {
+		if text := htmlToText(comment, base); text != "" {
+			detail.Comments = append(detail.Comments, text)
+		}
+	})
+	parseBasicInfo(doc, &detail)
+	detail.Markdown = renderBugMarkdown(detail)
+	return detail
+}
+
+// assigneeSuffix is the "于 <date>" ZenTao appends to the assignee.
+var assigneeSuffix = regexp.MustCompile(`\s+(于|on)\s+\d{4}-.*$`)
+
+// parseBasicInfo reads status, assignee and severity from the label/value
+// rows of the basic-info table.
+func parseBasicInfo(doc *goquery.Document, detail *BugDetail) {
+	doc.Find("th").Each(func(_ int, th *goquery.Selection) {
+		label := strings.TrimRight(collapseSpace(th.Text()), ":：")
+		cell := th.NextFiltered("td")
+		if cell.Length() == 0 {
+			return
+		}
+		value := collapseSpace(cell.Text())
+		switch label {
+		case "Bug状态", "状态", "Status":
+			if detail.Status == "" {
+				detail.Status = value
+			}
+		case "当前指派", "指派给", "Assigned To", "AssignedTo":
+			if detail.AssignedTo == "" {
+				detail.AssignedTo = assigneeSuffix.ReplaceAllString(value, "")
+			}
+		case "严重程度", "Severity":
+			if detail.Severity == "" {
+				marker := cell.Find("[title], [data-severity]").First()
+				detail.Severity = normalizeSeverity(strings.Join([]string{
+					value, marker.AttrOr("data-severity", ""), marker.AttrOr("title", ""),
+				}, " "))
+			}
+		}
+	})
+}
+
+func parseAttachments(sel *goquery.Selection, base *url.URL) []BugAttachment {
+	var files []BugAttachment
+	sel.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		href := link.AttrOr("href", "")
+		if !strings.Contains(href, "file-") && !strings.Contains(href, "m=file") {
+			// Edit and delete buttons sit next to each file.
+			return
+		}
+		if strings.Contains(href, "file-delete") || strings.Contains(href, "f=delete") ||
+			strings.Contains(href, "file-edit") || strings.Contains(href, "f=edit") {
+			return
+		}
+		name := collapseSpace(link.Text())
+		if name == "" {
+			name = link.AttrOr("title", "")
+		}
+		if name == "" {
+			return
+		}
+		files = append(files, BugAttachment{Name: name, URL: resolveLink(base, href)})
+	})
+	return files
+}
+
+func parseHistory(sel *goquery.Selection) []string {
+	var entries []string
+	sel.Find(".histories-list > li, ol > li").Each(func(_ int, item *goquery.Selection) {
+		if text := strings.TrimPrefix(htmlToText(item, nil), "- "); text != "" {
+			entries = append(entries, strings.ReplaceAll(text, "\n", " "))
+		}
+	})
+	return entries
+}
+
+// stepMarkers maps the section labels of ZenTao's default steps template to
+// the field they start.
+var stepMarkers = map[string]string{
+	"步骤": "steps", "steps": "steps",
+	"结果": "actual", "result": "actual", "results": "actual", "actual": "actual",
+	"期望": "expected", "expect": "expected", "expected": "expected",
+}
+
+var stepMarkerPattern = regexp.MustCompile(`^\s*[\[【]\s*([^\]】]+?)\s*[\]】]\s*(.*)$`)
+
+// splitStepSections splits the steps field on the [步骤]/[结果]/[期望]
+// labels ZenTao pre-fills. Text without labels is all steps.
+func splitStepSections(text string) (steps, actual, expected string) {
+	sections := map[string][]string{}
+	current := "steps"
+	for _, line := range strings.Split(text, "\n") {
+		if match := stepMarkerPattern.FindStringSubmatch(line); match != nil {
+			if field, ok := stepMarkers[strings.ToLower(match[1])]; ok {
+				current = field
+				line = match[2]
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+			}
+		}
+		sections[current] = append(sections[current], line)
+	}
+	join := func(field string) string {
+		return strings.TrimSpace(strings.Join(sections[field], "\n"))
+	}
+	return join("steps"), join("actual"), join("expected")
+}
+
+// htmlToText flattens sel into lines of text, keeping list bullets, links
+// and images in markdown form. base resolves relative links; nil keeps
+// them as they are.
+func htmlToText(sel *goquery.Selection, base *url.URL) string {
+	var b strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			b.WriteString(node.Data)
+			return
+		case html.ElementNode:
+		default:
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+			return
+		}
+		switch node.Data {
+		case "script", "style":
+			return
+		case "br":
+			b.WriteString("\n")
+			return
+		case "img":
+			if src := nodeAttr(node, "src"); src != "" {
+				fmt.Fprintf(&b, "![%s](%s)", nodeAttr(node, "alt"), resolveLink(base, src))
+			}
+			return
+		case "li":
+			b.WriteString("\n- ")
+		case "p", "div", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "pre", "blockquote":
+			b.WriteString("\n")
+		}
+		href := ""
+		if node.Data == "a" {
+			href = nodeAttr(node, "href")
+			if strings.HasPrefix(href, "javascript:") || strings.HasPrefix(href, "#") {
+				href = ""
+			}
+		}
+		if href != "" {
+			b.WriteString("[")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		switch node.Data {
+		case "a":
+			if href != "" {
+				fmt.Fprintf(&b, "](%s)", resolveLink(base, href))
+			}
+		case "p", "div", "tr", "h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "pre", "blockquote":
+			b.WriteString("\n")
+		case "td", "th":
+			b.WriteString(" ")
+		}
+	}
+	for _, node := range sel.Nodes {
+		walk(node)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if line = collapseSpace(line); line != "" && line != "-" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func nodeAttr(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func resolveLink(base *url.URL, href string) string {
+	if base == nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func containsAny(s string, needles ...string) bool {
+	for _, needle := range needles {
+		if strings.Contains(s, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderBugMarkdown(detail BugDetail) string {
+	var b strings.Builder
+	if detail.ID > 0 {
+		fmt.Fprintf(&b, "# #%d %s\n", detail.ID, detail.Title)
+	} else {
+		fmt.Fprintf(&b, "# %s\n", detail.Title)
+	}
+	section := func(heading, body string) {
+		if body != "" {
+			fmt.Fprintf(&b, "\n## %s\n\n%s\n", heading, body)
+		}
+	}
+	section("重现步骤", detail.Steps)
+	section("实际结果", detail.Actual)
+	section("期望结果", detail.Expected)
+	if len(detail.Attachments) > 0 {
+		b.WriteString("\n## 附件\n\n")
+		for _, file := range detail.Attachments {
+			fmt.Fprintf(&b, "- [%s](%s)\n", file.Name, file.URL)
+		}
+	}
+	if len(detail.History) > 0 {
+		b.WriteString("\n## 历史记录\n\n")
+		for _, entry := range detail.History {
+			fmt.Fprintf(&b, "- %s\n", entry)
+		}
+	}
+	return b.String()
+}
+
+// bugPreviews fetches the first lines of the steps of new level-1/2 bugs
+// for the change notification, spending at most detailFetchTimeout on all
+// of them. Bugs whose detail cannot be fetched are listed by title alone.
+func (m *Monitor) bugPreviews(bugs []Bug) string {
+	ctx, cancel := context.WithTimeout(context.Background(), detailFetchTimeout)
+	defer cancel()
+	var blocks []string
+	for _, bug := range bugs {
+		if len(blocks) == previewBugs {
+			break
+		}
+		block := fmt.Sprintf("#%d %s", bug.ID, truncateText(bug.Title, previewLineRunes))
+		detail, err := m.bugDetail(ctx, bug.ID)
+		if err != nil {
+			m.addLog("warn", fmt.Sprintf("Fetching bug #%d for the notification failed: %v", bug.ID, err), 0)
+		} else {
+			for _, line := range firstLines(stripMarkdown(detail.Steps), previewLines) {
+				block += "\n" + truncateText(line, previewLineRunes)
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// newSevereBugs returns the unmuted level-1 and level-2 bugs in curr that
+// were not in prev, newest first.
+func newSevereBugs(prev, curr []Bug) []Bug {
+	seen := make(map[int]bool, len(prev))
+	for _, bug := range prev {
+		seen[bug.ID] = true
+	}
+	var added []Bug
+	for _, bug := range curr {
+		if !seen[bug.ID] && !bug.Muted && (bug.Severity == "critical" || bug.Severity == "severe") {
+			added = append(added, bug)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].ID > added[j].ID })
+	return added
+}
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink  = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// stripMarkdown drops images and keeps only the text of links, for places
+// that show plain text such as notifications.
+func stripMarkdown(text string) string {
+	text = markdownImage.ReplaceAllString(text, "")
+	return markdownLink.ReplaceAllString(text, "$1")
+}
+
+func firstLines(text string, limit int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == limit {
+			break
+		}
+	}
+	return lines
+}
+
+func truncateText(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
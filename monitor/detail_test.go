@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveConfigClearsDetailCache(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	m.monitoringEnabled = false
+	cache := func() {
+		m.mu.Lock()
+		m.detailCache = map[int]cachedBugDetail{7: {detail: BugDetail{ID: 7}, expires: time.Now().Add(time.Hour)}}
+		m.mu.Unlock()
+	}
+	cached := func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		_, ok := m.detailCache[7]
+		return ok
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(*Config)
+		clear bool
+	}{
+		{"interval", func(cfg *Config) { cfg.IntervalMinutes = 30 }, false},
+		{"url", func(cfg *Config) { cfg.URL = "http://other.example.com/my-work-bug.html" }, true},
+		{"source", func(cfg *Config) { cfg.Source = sourceRelay; cfg.RelayURL = "http://relay.example.com" }, true},
+		{"relay url", func(cfg *Config) { cfg.RelayURL = "http://relay2.example.com" }, true},
+		{"targets", func(cfg *Config) {
+			cfg.Targets = []Target{{Name: "商城", URL: "http://other.example.com/bug-browse-3.html"}}
+		}, true},
+	}
+	for _, tt := range tests {
+		cache()
+		cfg := m.CurrentConfig()
+		tt.edit(&cfg)
+		if err := m.SaveConfig(cfg); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if cached() == tt.clear {
+			t.Errorf("changing %s: detail cached = %v, want %v", tt.name, cached(), !tt.clear)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package monitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	historyDirName          = "history"
+	historyDayLayout        = "2006-01-02"
+	historySegmentExt       = ".jsonl"
+	historyCompactExt       = ".compact.jsonl"
+	historyRetentionDays    = 180
+	historyCompactAfterDays = 7
+
+	historyStatusOK    = "ok"
+	historyStatusError = "error"
+
+	bucketHour = "hour"
+	bucketDay  = "day"
+	bucketWeek = "week"
+)
+
+// historyStore keeps one JSON-lines segment per local day. The current day
+// is only ever appended to; once a segment is older than
+// historyCompactAfterDays its unchanged samples are folded into the record
+// before them, and it is deleted after historyRetentionDays.
+type historyStore struct {
+	mu            sync.Mutex
+	dir           string
+	maintainedDay string
+}
+
+func newHistoryStore(dir string) *historyStore {
+	_ = os.MkdirAll(dir, 0o755)
+	return &historyStore{dir: dir}
+}
+
+func (h *historyStore) Append(record HistoryRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	day := record.Timestamp.Local().Format(historyDayLayout)
+	if day != h.maintainedDay {
+		h.maintainedDay = day
+		_ = h.maintainLocked(record.Timestamp)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(h.dir, day+historySegmentExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Query returns the records between from and to (inclusive) aggregated into
+// hour, day or week buckets, oldest first.
+func (h *historyStore) Query(from, to time.Time, bucket string) ([]HistoryPoint, error) {
+	if bucket == "" {
+		bucket = bucketHour
+	}
+	if bucket != bucketHour && bucket != bucketDay && bucket != bucketWeek {
+		return nil, fmt.Errorf("unknown bucket %q", bucket)
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if to.Before(from) {
+		return nil, errors.New("history range ends before it starts")
+	}
+
+	records, err := h.read(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]HistoryPoint, 0)
+	var durations int64
+	for _, record := range records {
+		start := bucketStart(record.Timestamp, bucket)
+		if len(points) == 0 || !points[len(points)-1].Start.Equal(start) {
+			if len(points) > 0 {
+				points[len(points)-1].AvgDurationMs = durations / int64(points[len(points)-1].Samples)
+			}
+			points = append(points, HistoryPoint{Start: start, MinTotal: -1})
+			durations = 0
+		}
+		point := &points[len(points)-1]
+		point.Samples += record.samples()
+		point.Failures += record.failures()
+		durations += record.durations()
+		if record.Status != historyStatusOK {
+			continue
+		}
+		point.Total = record.Total
+		point.Severity = record.Severity
+		if point.MinTotal < 0 || record.Total < point.MinTotal {
+			point.MinTotal = record.Total
+		}
+		if record.Total > point.MaxTotal {
+			point.MaxTotal = record.Total
+		}
+	}
+	if len(points) > 0 {
+		points[len(points)-1].AvgDurationMs = durations / int64(points[len(points)-1].Samples)
+	}
+	for i := range points {
+		if points[i].MinTotal < 0 {
+			points[i].MinTotal = 0
+		}
+	}
+	return points, nil
+}
+
+// Records returns the raw samples between from and to, oldest first.
+func (h *historyStore) Records(from, to time.Time) ([]HistoryRecord, error) {
+	if to.IsZero() {
+		to = time.Now()
+	}
+	return h.read(from, to)
+}
+
+func (h *historyStore) read(from, to time.Time) ([]HistoryRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	segments, err := h.segments()
+	if err != nil {
+		return nil, err
+	}
+	firstDay := from.Local().Format(historyDayLayout)
+	lastDay := to.Local().Format(historyDayLayout)
+
+	records := make([]HistoryRecord, 0)
+	for _, segment := range segments {
+		if segment.day < firstDay || segment.day > lastDay {
+			continue
+		}
+		segmentRecords, err := readHistorySegment(segment.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range segmentRecords {
+			if record.Timestamp.Before(from) || record.Timestamp.After(to) {
+				continue
+			}
+			records = append(records, record)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+func (h *historyStore) maintainLocked(now time.Time) error {
+	segments, err := h.segments()
+	if err != nil {
+		return err
+	}
+	today := now.Local()
+	dropBefore := today.AddDate(0, 0, -historyRetentionDays).Format(historyDayLayout)
+	compactBefore := today.AddDate(0, 0, -historyCompactAfterDays).Format(historyDayLayout)
+	for _, segment := range segments {
+		switch {
+		case segment.day < dropBefore:
+			_ = os.Remove(segment.path)
+		case segment.day < compactBefore && !segment.compacted:
+			if err := compactHistorySegment(segment.path, filepath.Join(h.dir, segment.day+historyCompactExt)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type historySegment struct {
+	day       string
+	path      string
+	compacted bool
+}
+
+func (h *historyStore) segments() ([]historySegment, error) {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	segments := make([]historySegment, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, historySegmentExt) {
+			continue
+		}
+		segment := historySegment{path: filepath.Join(h.dir, name)}
+		if strings.HasSuffix(name, historyCompactExt) {
+			segment.day = strings.TrimSuffix(name, historyCompactExt)
+			segment.compacted = true
+		} else {
+			segment.day = strings.TrimSuffix(name, historySegmentExt)
+		}
+		if _, err := time.Parse(historyDayLayout, segment.day); err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].day < segments[j].day
+	})
+	return segments, nil
+}
+
+// readHistorySegment skips lines it cannot decode, so a record cut short by
+// a crash does not hide the rest of the day.
+func readHistorySegment(path string) ([]HistoryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]HistoryRecord, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// compactHistorySegment keeps the first sample of every run of samples with
+// the same status and counts within an hour, folding the rest of the run
+// into its Samples, Failures and AvgDurationMs so bucketed queries count
+// the same samples and failures as before compaction.
+func compactHistorySegment(rawPath, compactPath string) error {
+	records, err := readHistorySegment(rawPath)
+	if err != nil {
+		return err
+	}
+	if existing, err := readHistorySegment(compactPath); err == nil {
+		records = append(existing, records...)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(records[j].Timestamp)
+	})
+
+	kept := make([]HistoryRecord, 0)
+	var durations []int64
+	for _, record := range records {
+		if n := len(kept); n > 0 && sameHistoryRun(kept[n-1], record) {
+			kept[n-1].Samples += record.samples()
+			kept[n-1].Failures += record.failures()
+			durations[n-1] += record.durations()
+			continue
+		}
+		duration := record.durations()
+		record.Samples, record.Failures = record.samples(), record.failures()
+		kept = append(kept, record)
+		durations = append(durations, duration)
+	}
+
+	var buf strings.Builder
+	for i, record := range kept {
+		record.AvgDurationMs = durations[i] / int64(record.Samples)
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := writeFileAtomic(compactPath, []byte(buf.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Remove(rawPath)
+}
+
+// sameHistoryRun reports whether next can be folded into kept: same status
+// and counts, and the same hour so hourly buckets stay exact.
+func sameHistoryRun(kept, next HistoryRecord) bool {
+	return kept.Status == next.Status &&
+		kept.Total == next.Total &&
+		kept.Severity == next.Severity &&
+		bucketStart(kept.Timestamp, bucketHour).Equal(bucketStart(next.Timestamp, bucketHour))
+}
+
+// samples is how many scrapes r stands for: one for a raw record, the run
+// it replaced for a compacted one.
+func (r HistoryRecord) samples() int {
+	if r.Samples > 0 {
+		return r.Samples
+	}
+	return 1
+}
+
+func (r HistoryRecord) failures() int {
+	if r.Samples > 0 {
+		return r.Failures
+	}
+	if r.Status != historyStatusOK {
+		return 1
+	}
+	return 0
+}
+
+// durations is the summed scrape time of the samples r stands for.
+func (r HistoryRecord) durations() int64 {
+	if r.Samples > 0 {
+		return r.AvgDurationMs * int64(r.Samples)
+	}
+	return r.DurationMs
+}
+
+func bucketStart(ts time.Time, bucket string) time.Time {
+	local := ts.Local()
+	switch bucket {
+	case bucketDay:
+		return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	case bucketWeek:
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, local.Location())
+	}
+}
@@ -0,0 +1,140 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// historyAt returns hour:minute local time, days ago.
+func historyAt(days, hour, minute int) time.Time {
+	day := time.Now().AddDate(0, 0, -days)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+}
+
+func okRecord(at time.Time, total int, durationMs int64) HistoryRecord {
+	return HistoryRecord{
+		Timestamp:  at,
+		Status:     historyStatusOK,
+		DurationMs: durationMs,
+		Total:      total,
+		Severity:   SeverityCounts{Major: total},
+	}
+}
+
+func errorRecord(at time.Time, durationMs int64) HistoryRecord {
+	return HistoryRecord{Timestamp: at, Status: historyStatusError, DurationMs: durationMs, Error: "timeout"}
+}
+
+func TestHistoryCompactionKeepsAggregates(t *testing.T) {
+	dir := t.TempDir()
+	h := newHistoryStore(dir)
+	const days = historyCompactAfterDays + 3
+	for _, record := range []HistoryRecord{
+		okRecord(historyAt(days, 9, 0), 3, 100),
+		okRecord(historyAt(days, 9, 10), 3, 300),
+		okRecord(historyAt(days, 9, 20), 3, 200),
+		errorRecord(historyAt(days, 9, 30), 1000),
+		errorRecord(historyAt(days, 9, 40), 3000),
+		okRecord(historyAt(days, 10, 5), 3, 100),
+		okRecord(historyAt(days, 10, 15), 4, 100),
+		okRecord(historyAt(days, 10, 25), 4, 300),
+	} {
+		if err := h.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, to := historyAt(days, 0, 0), historyAt(days, 23, 59)
+	query := func(bucket string) []HistoryPoint {
+		t.Helper()
+		points, err := h.Query(from, to, bucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return points
+	}
+	rawHours, rawDays := query(bucketHour), query(bucketDay)
+
+	if err := h.maintainLocked(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	day := historyAt(days, 0, 0).Format(historyDayLayout)
+	if _, err := os.Stat(filepath.Join(dir, day+historySegmentExt)); !os.IsNotExist(err) {
+		t.Errorf("raw segment still present after compaction: %v", err)
+	}
+	records, err := h.Records(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type run struct {
+		samples, failures int
+		avg               int64
+	}
+	var runs []run
+	for _, record := range records {
+		runs = append(runs, run{record.Samples, record.Failures, record.AvgDurationMs})
+	}
+	wantRuns := []run{{3, 0, 200}, {2, 2, 2000}, {1, 0, 100}, {2, 0, 200}}
+	if !reflect.DeepEqual(runs, wantRuns) {
+		t.Errorf("compacted runs = %+v, want %+v", runs, wantRuns)
+	}
+
+	hours, daily := query(bucketHour), query(bucketDay)
+	if !reflect.DeepEqual(hours, rawHours) {
+		t.Errorf("hourly points after compaction = %+v, want %+v", hours, rawHours)
+	}
+	if !reflect.DeepEqual(daily, rawDays) {
+		t.Errorf("daily points after compaction = %+v, want %+v", daily, rawDays)
+	}
+	wantHours := []HistoryPoint{
+		{Start: historyAt(days, 9, 0), Samples: 5, Failures: 2, Total: 3, MinTotal: 3, MaxTotal: 3, Severity: SeverityCounts{Major: 3}, AvgDurationMs: 920},
+		{Start: historyAt(days, 10, 0), Samples: 3, Total: 4, MinTotal: 3, MaxTotal: 4, Severity: SeverityCounts{Major: 4}, AvgDurationMs: 166},
+	}
+	if !reflect.DeepEqual(hours, wantHours) {
+		t.Errorf("hourly points = %+v, want %+v", hours, wantHours)
+	}
+	wantDays := []HistoryPoint{
+		{Start: historyAt(days, 0, 0), Samples: 8, Failures: 2, Total: 4, MinTotal: 3, MaxTotal: 4, Severity: SeverityCounts{Major: 4}, AvgDurationMs: 637},
+	}
+	if !reflect.DeepEqual(daily, wantDays) {
+		t.Errorf("daily points = %+v, want %+v", daily, wantDays)
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	dir := t.TempDir()
+	h := newHistoryStore(dir)
+	for _, days := range []int{historyRetentionDays + 5, historyRetentionDays - 1, 1} {
+		if err := h.Append(okRecord(historyAt(days, 12, 0), days, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.maintainLocked(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := h.Records(historyAt(historyRetentionDays+10, 0, 0), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var totals []int
+	for _, record := range records {
+		totals = append(totals, record.Total)
+	}
+	if want := []int{historyRetentionDays - 1, 1}; !reflect.DeepEqual(totals, want) {
+		t.Errorf("records after retention = %v, want %v", totals, want)
+	}
+	segments, err := h.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compacted []bool
+	for _, segment := range segments {
+		compacted = append(compacted, segment.compacted)
+	}
+	if want := []bool{true, false}; !reflect.DeepEqual(compacted, want) {
+		t.Errorf("segments compacted = %v, want the old one compacted and yesterday's raw", compacted)
+	}
+}
@@ -0,0 +1,254 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hook event types. A hook with no Events receives all of them.
+const (
+	HookEventChange       = "change"
+	HookEventNotification = "notification"
+	HookEventHealth       = "health"
+)
+
+const (
+	defaultHookTimeout = 10
+	maxHookTimeout     = 300
+	maxHookOutput      = 4096
+
+	// hookWaitDelay bounds how long a timed-out hook's output is drained
+	// after it is killed; a child that inherited stdout would otherwise
+	// keep Run waiting until it exits too.
+	hookWaitDelay = 3 * time.Second
+)
+
+// HookEvent is what an exec hook receives as JSON on stdin. The main fields
+// are repeated in BUGDOG_* environment variables for one-line scripts. Total
+// and Severity count only the bugs not muted by ignore rules.
+type HookEvent struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Title     string          `json:"title,omitempty"`
+	Message   string          `json:"message,omitempty"`
+	Total     int             `json:"total"`
+	Delta     int             `json:"delta,omitempty"`
+	Severity  SeverityCounts  `json:"severity"`
+	Health    string          `json:"health,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Change    *ChangeLogEntry `json:"change,omitempty"`
+}
+
+func sanitizeExecHooks(hooks []ExecHook) []ExecHook {
+	cleaned := make([]ExecHook, 0, len(hooks))
+	for _, hook := range hooks {
+		hook.Command = strings.TrimSpace(hook.Command)
+		if hook.Command == "" {
+			continue
+		}
+		hook.Name = strings.TrimSpace(hook.Name)
+		if hook.Name == "" {
+			hook.Name = hook.Command
+		}
+		if hook.TimeoutSeconds < 1 {
+			hook.TimeoutSeconds = defaultHookTimeout
+		}
+		if hook.TimeoutSeconds > maxHookTimeout {
+			hook.TimeoutSeconds = maxHookTimeout
+		}
+		cleaned = append(cleaned, hook)
+	}
+	return cleaned
+}
+
+func (h ExecHook) wants(eventType string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, name := range h.Events {
+		if name == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// runHooks starts every hook subscribed to event.Type in the background, so
+// a slow script never holds up a sync.
+func (m *Monitor) runHooks(event HookEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	for _, hook := range m.currentConfig().ExecHooks {
+		if hook.wants(event.Type) {
+			go m.runHook(hook, event)
+		}
+	}
+}
+
+// hasHooks reports whether any configured hook receives eventType.
+func (m *Monitor) hasHooks(eventType string) bool {
+	for _, hook := range m.currentConfig().ExecHooks {
+		if hook.wants(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// runNotificationHooks hands a notification to the hooks subscribed to
+// them. They run whether or not desktop notifications are enabled, and count
+// only the bugs not muted by ignore rules, like the notification itself.
+func (m *Monitor) runNotificationHooks(title, message string) {
+	stats := m.GetStats()
+	m.runHooks(HookEvent{
+		Type:     HookEventNotification,
+		Title:    title,
+		Message:  message,
+		Total:    stats.Actionable,
+		Severity: stats.ActionableSeverity,
+	})
+}
+
+// HookResult is the outcome of one hook run by TestNotificationHooks; Err is
+// nil when it exited 0.
+type HookResult struct {
+	Name string
+	Err  error
+}
+
+// TestNotificationHooks runs each notification hook with a test event, one
+// after another, and waits for them all.
+func (m *Monitor) TestNotificationHooks(title, message string) []HookResult {
+	stats := m.GetStats()
+	event := HookEvent{
+		Type:      HookEventNotification,
+		Timestamp: time.Now(),
+		Title:     title,
+		Message:   message,
+		Total:     stats.Actionable,
+		Severity:  stats.ActionableSeverity,
+	}
+	var results []HookResult
+	for _, hook := range m.currentConfig().ExecHooks {
+		if hook.wants(event.Type) {
+			results = append(results, HookResult{Name: hook.Name, Err: m.runHook(hook, event)})
+		}
+	}
+	return results
+}
+
+// runHook runs one hook to completion and logs its exit status with the
+// captured stdout and stderr. It returns nil only when the hook exited 0.
+func (m *Monitor) runHook(hook ExecHook, event HookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	timeout := time.Duration(hook.TimeoutSeconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), hookEnv(event, payload)...)
+	stdout := &limitedBuffer{limit: maxHookOutput}
+	stderr := &limitedBuffer{limit: maxHookOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = hookWaitDelay
+
+	started := time.Now()
+	err = cmd.Run()
+	elapsed := time.Since(started).Round(time.Millisecond)
+
+	var failure error
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		failure = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		failure = fmt.Errorf("exited with status %d in %s", exitErr.ExitCode(), elapsed)
+	case errors.Is(err, exec.ErrWaitDelay):
+		failure = fmt.Errorf("exited with status 0 in %s but left its output open", elapsed)
+	case err != nil:
+		failure = fmt.Errorf("failed to start: %v", err)
+	}
+	if event.Type == HookEventNotification {
+		m.metrics.observeNotification("exec", failure)
+	}
+	level, outcome := "info", fmt.Sprintf("exited with status 0 in %s", elapsed)
+	if failure != nil {
+		level, outcome = "error", failure.Error()
+	}
+	m.addLogEntry(LogEntry{
+		Level:   level,
+		Message: fmt.Sprintf("Hook %s (%s) %s", hook.Name, event.Type, outcome),
+		Output:  hookOutput(stdout.String(), stderr.String()),
+	})
+	return failure
+}
+
+func hookEnv(event HookEvent, payload []byte) []string {
+	return []string{
+		"BUGDOG_EVENT=" + event.Type,
+		"BUGDOG_TIMESTAMP=" + event.Timestamp.Format(time.RFC3339),
+		"BUGDOG_TITLE=" + event.Title,
+		"BUGDOG_MESSAGE=" + event.Message,
+		"BUGDOG_TOTAL=" + strconv.Itoa(event.Total),
+		"BUGDOG_DELTA=" + strconv.Itoa(event.Delta),
+		"BUGDOG_CRITICAL=" + strconv.Itoa(event.Severity.Critical),
+		"BUGDOG_SEVERE=" + strconv.Itoa(event.Severity.Severe),
+		"BUGDOG_MAJOR=" + strconv.Itoa(event.Severity.Major),
+		"BUGDOG_MINOR=" + strconv.Itoa(event.Severity.Minor),
+		"BUGDOG_HEALTH=" + event.Health,
+		"BUGDOG_ERROR=" + event.Error,
+		"BUGDOG_JSON=" + string(payload),
+	}
+}
+
+func hookOutput(stdout, stderr string) string {
+	stdout = strings.TrimSpace(stdout)
+	stderr = strings.TrimSpace(stderr)
+	switch {
+	case stdout != "" && stderr != "":
+		return "stdout:\n" + stdout + "\nstderr:\n" + stderr
+	case stderr != "":
+		return "stderr:\n" + stderr
+	default:
+		return stdout
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written and discards the rest,
+// so a chatty script cannot fill the log. It wraps rather than embeds the
+// buffer so io.Copy cannot bypass Write through Buffer.ReadFrom.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.limit - b.buf.Len(); n > room {
+		p, b.truncated = p[:max(room, 0)], true
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n…(truncated)"
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use sh")
+	}
+}
+
+func TestHookWithLingeringChildReturns(t *testing.T) {
+	skipWithoutShell(t)
+	m := New(Options{DataDir: t.TempDir()})
+	hook := ExecHook{
+		Name:           "lingering",
+		Command:        "sh",
+		Args:           []string{"-c", "sleep 30 & echo started"},
+		TimeoutSeconds: 1,
+	}
+	started := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- m.runHook(hook, HookEvent{Type: HookEventNotification}) }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("runHook reported success for a hook that left its output open")
+		}
+		if elapsed := time.Since(started); elapsed > hookWaitDelay+5*time.Second {
+			t.Errorf("runHook took %s", elapsed)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("runHook is still waiting on the child's output")
+	}
+}
+
+func TestNotificationHooksRunWithNotificationsOff(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "hook.out")
+	m := New(Options{DataDir: dir, Headless: true})
+	cfg := defaultConfig()
+	cfg.EnableNotifications = false
+	cfg.EnableSound = false
+	cfg.ExecHooks = sanitizeExecHooks([]ExecHook{{
+		Name:    "record",
+		Command: "sh",
+		Args:    []string{"-c", `printf '%s' "$BUGDOG_MESSAGE" > "$1"`, "sh", out},
+		Events:  []string{HookEventNotification},
+	}})
+	m.config = cfg
+
+	m.maybeNotifyChange("等级变化：一级 0→1，当前总数 1", nil)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		data, err := os.ReadFile(out)
+		if err == nil && strings.Contains(string(data), "一级 0→1") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("notification hook did not run: %v %q", err, data)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	for _, entry := range m.GetLogs() {
+		if entry.Level == "notify" {
+			t.Errorf("notification shown although notifications are off: %s", entry.Message)
+		}
+	}
+}
+
+func TestChangeHooksIgnoreMutedBugs(t *testing.T) {
+	skipWithoutShell(t)
+	z := newFakeZenTao(t)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "change.out")
+	url := z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 31, Severity: 3, Title: "按钮错位", Status: "激活"},
+	))
+	m, _ := newTestMonitor(t, dir, url)
+	m.config.IgnoreRules = sanitizeIgnoreRules([]IgnoreRule{{TitlePattern: "^\\[已知\\]"}})
+	m.config.ExecHooks = sanitizeExecHooks([]ExecHook{{
+		Name:    "record",
+		Command: "sh",
+		Args:    []string{"-c", `printf '%s %s\n' "$BUGDOG_DELTA" "$BUGDOG_TOTAL" >> "$1"`, "sh", out},
+		Events:  []string{HookEventChange},
+	}})
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+
+	z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 31, Severity: 3, Title: "按钮错位", Status: "激活"},
+		fakeBug{ID: 32, Severity: 1, Title: "[已知] 旧版浏览器白屏", Status: "激活"},
+	))
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+	z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 31, Severity: 3, Title: "按钮错位", Status: "激活"},
+		fakeBug{ID: 32, Severity: 1, Title: "[已知] 旧版浏览器白屏", Status: "激活"},
+		fakeBug{ID: 33, Severity: 2, Title: "支付超时", Status: "激活"},
+	))
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	var data []byte
+	for {
+		var err error
+		data, err = os.ReadFile(out)
+		if err == nil && len(data) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("change hook did not run: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	// Give a wrongly fired hook for the muted bug time to land too.
+	time.Sleep(200 * time.Millisecond)
+	if data, _ = os.ReadFile(out); string(data) != "1 2\n" {
+		t.Errorf("change hook runs = %q, want one run with delta 1 and 2 actionable bugs", data)
+	}
+	if changes := m.GetChangeLog(); len(changes) != 2 {
+		t.Errorf("change log has %d entries, want both raw changes", len(changes))
+	}
+}
+
+func TestNotificationHooksCountActionableBugs(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	out := filepath.Join(dir, "hook.out")
+	m := New(Options{DataDir: dir, Headless: true})
+	m.config.ExecHooks = sanitizeExecHooks([]ExecHook{{
+		Name:    "record",
+		Command: "sh",
+		Args:    []string{"-c", `printf '%s' "$BUGDOG_TOTAL" > "$1"`, "sh", out},
+		Events:  []string{HookEventNotification},
+	}})
+	m.stats = Stats{Total: 3, Actionable: 2, Muted: 1}
+
+	for _, result := range m.TestNotificationHooks(notifyTitle, "测试通知") {
+		if result.Err != nil {
+			t.Fatalf("hook %s: %v", result.Name, result.Err)
+		}
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "2" {
+		t.Errorf("BUGDOG_TOTAL = %q (%v), want the 2 actionable bugs", data, err)
+	}
+}
@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// IgnoreRule mutes the bugs matching every criterion it sets: a bug ID, a
+// title regular expression, a module or an opener. A rule with an Expires
+// time stops applying after it; a zero Expires never ends.
+type IgnoreRule struct {
+	BugID        int       `json:"bugId"`
+	TitlePattern string    `json:"titlePattern"`
+	Module       string    `json:"module"`
+	OpenedBy     string    `json:"openedBy"`
+	Note         string    `json:"note"`
+	Expires      time.Time `json:"expires"`
+}
+
+func (r IgnoreRule) empty() bool {
+	return r.BugID < 1 && r.TitlePattern == "" && r.Module == "" && r.OpenedBy == ""
+}
+
+func (r IgnoreRule) active(now time.Time) bool {
+	return r.Expires.IsZero() || now.Before(r.Expires)
+}
+
+func sanitizeIgnoreRules(rules []IgnoreRule) []IgnoreRule {
+	cleaned := make([]IgnoreRule, 0, len(rules))
+	for _, rule := range rules {
+		rule.TitlePattern = strings.TrimSpace(rule.TitlePattern)
+		rule.Module = strings.TrimSpace(rule.Module)
+		rule.OpenedBy = strings.TrimSpace(rule.OpenedBy)
+		rule.Note = strings.TrimSpace(rule.Note)
+		if rule.empty() {
+			continue
+		}
+		cleaned = append(cleaned, rule)
+	}
+	return cleaned
+}
+
+// validateIgnoreRules rejects title patterns that do not compile, so a typo
+// is reported when saving rather than silently muting nothing.
+func validateIgnoreRules(rules []IgnoreRule) error {
+	for i, rule := range rules {
+		pattern := strings.TrimSpace(rule.TitlePattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("忽略规则 %d 的标题正则无效: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ignoreMatcher is the compiled form of the rules active at one moment.
+type ignoreMatcher struct {
+	rules  []IgnoreRule
+	titles []*regexp.Regexp
+}
+
+func newIgnoreMatcher(rules []IgnoreRule, now time.Time) ignoreMatcher {
+	var matcher ignoreMatcher
+	for _, rule := range rules {
+		if !rule.active(now) {
+			continue
+		}
+		var title *regexp.Regexp
+		if rule.TitlePattern != "" {
+			compiled, err := regexp.Compile(rule.TitlePattern)
+			if err != nil {
+				continue
+			}
+			title = compiled
+		}
+		matcher.rules = append(matcher.rules, rule)
+		matcher.titles = append(matcher.titles, title)
+	}
+	return matcher
+}
+
+func (im ignoreMatcher) matches(bug Bug) bool {
+	for i, rule := range im.rules {
+		if rule.BugID > 0 && rule.BugID != bug.ID {
+			continue
+		}
+		if im.titles[i] != nil && !im.titles[i].MatchString(bug.Title) {
+			continue
+		}
+		if rule.Module != "" && !strings.EqualFold(rule.Module, bug.Module) {
+			continue
+		}
+		if rule.OpenedBy != "" && !strings.EqualFold(rule.OpenedBy, bug.OpenedBy) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// applyIgnoreRules marks the muted bugs and fills in the actionable and
+// muted counts. Total and Severity stay the raw list counts.
+func applyIgnoreRules(stats Stats, bugs []Bug, rules []IgnoreRule, now time.Time) (Stats, []Bug) {
+	matcher := newIgnoreMatcher(rules, now)
+	marked := make([]Bug, len(bugs))
+	stats.Muted = 0
+	stats.ActionableSeverity = stats.Severity
+	for i, bug := range bugs {
+		bug.Muted = matcher.matches(bug)
+		marked[i] = bug
+		if !bug.Muted {
+			continue
+		}
+		stats.Muted++
+		switch bug.Severity {
+		case "critical":
+			stats.ActionableSeverity.Critical--
+		case "severe":
+			stats.ActionableSeverity.Severe--
+		case "major":
+			stats.ActionableSeverity.Major--
+		case "minor":
+			stats.ActionableSeverity.Minor--
+		}
+	}
+	stats.Actionable = max(stats.Total-stats.Muted, 0)
+	counts := &stats.ActionableSeverity
+	counts.Critical, counts.Severe = max(counts.Critical, 0), max(counts.Severe, 0)
+	counts.Major, counts.Minor = max(counts.Major, 0), max(counts.Minor, 0)
+	return stats, marked
+}
+
+// reapplyIgnoreRules recomputes the current counts after the rules change,
+// so the window reflects them without waiting for the next sync.
+func (m *Monitor) reapplyIgnoreRules() {
+	m.mu.Lock()
+	if m.stats.LastUpdated.IsZero() {
+		m.mu.Unlock()
+		return
+	}
+	m.stats, m.bugs = applyIgnoreRules(m.stats, m.bugs, m.config.IgnoreRules, time.Now())
+	m.stats, m.bugs = m.flagUnread(m.stats, m.bugs)
+	stats, bugs := m.stats, m.bugs
+	m.mu.Unlock()
+	_ = m.saveState(stats, bugs)
+	m.emitStats()
+}
+
+func ignoreRulesChanged(previous, cfg Config) bool {
+	if len(previous.IgnoreRules) != len(cfg.IgnoreRules) {
+		return true
+	}
+	for i := range cfg.IgnoreRules {
+		if previous.IgnoreRules[i] != cfg.IgnoreRules[i] {
+			return true
+		}
+	}
+	return false
+}
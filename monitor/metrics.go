@@ -0,0 +1,272 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failure kinds reported in bugdog_scrape_failures_total.
+const (
+	failureNetwork = "network"
+	failureTimeout = "timeout"
+	failureHTTP    = "http"
+	failureAuth    = "auth"
+	failureOther   = "other"
+)
+
+var scrapeDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25}
+
+// metrics holds the counters and the latency histogram served on /metrics.
+// Gauges are read from the monitor's current state at scrape time instead.
+type metrics struct {
+	mu            sync.Mutex
+	scrapes       map[string]float64
+	failures      map[[2]string]float64
+	notifications map[[2]string]float64
+	durations     map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		scrapes:       map[string]float64{},
+		failures:      map[[2]string]float64{},
+		notifications: map[[2]string]float64{},
+		durations:     map[string]*histogram{},
+	}
+}
+
+func (x *metrics) observeScrape(target string, duration time.Duration, err error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.scrapes[target]++
+	if err != nil {
+		x.failures[[2]string{target, failureKind(err)}]++
+	}
+	h := x.durations[target]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(scrapeDurationBuckets))}
+		x.durations[target] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range scrapeDurationBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (x *metrics) observeNotification(channel string, err error) {
+	result := "sent"
+	if err != nil {
+		result = "failed"
+	}
+	x.mu.Lock()
+	x.notifications[[2]string{channel, result}]++
+	x.mu.Unlock()
+}
+
+// failureKind sorts a scrape error into one of the failure* kinds.
+func failureKind(err error) string {
+	if errors.Is(err, ErrLoginRequired) {
+		return failureAuth
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.Code == http.StatusUnauthorized || statusErr.Code == http.StatusForbidden {
+			return failureAuth
+		}
+		return failureHTTP
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return failureTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return failureTimeout
+		}
+		return failureNetwork
+	}
+	return failureOther
+}
+
+// writeMetrics renders every metric in the Prometheus text exposition format.
+func (m *Monitor) writeMetrics(w io.Writer) {
+	cfg := m.currentConfig()
+	target := metricsTarget(cfg)
+	stats := m.GetStats()
+	bugs := m.GetBugs()
+	health := m.GetHealth()
+
+	// With extra targets bugdog_bugs and bugdog_bugs_open break down by
+	// target name, and the merged gauges drop the target label since they
+	// span every page.
+	pages := stats.Targets
+	merged := []string{}
+	if len(pages) == 0 {
+		pages = []TargetStats{{Name: target, Total: stats.Total, Severity: stats.Severity}}
+		merged = []string{"target", target}
+	}
+	mergedLabels := func(pairs ...string) string {
+		return labels(append(append([]string{}, merged...), pairs...)...)
+	}
+
+	p := &promWriter{w: w}
+	p.header("bugdog_bugs", "gauge", "Bugs in the last scrape by severity level.")
+	for _, page := range pages {
+		p.sample("bugdog_bugs", labels("target", page.Name, "severity", "critical"), float64(page.Severity.Critical))
+		p.sample("bugdog_bugs", labels("target", page.Name, "severity", "severe"), float64(page.Severity.Severe))
+		p.sample("bugdog_bugs", labels("target", page.Name, "severity", "major"), float64(page.Severity.Major))
+		p.sample("bugdog_bugs", labels("target", page.Name, "severity", "minor"), float64(page.Severity.Minor))
+	}
+	p.header("bugdog_bugs_open", "gauge", "Bugs in the last scrape.")
+	for _, page := range pages {
+		p.sample("bugdog_bugs_open", labels("target", page.Name), float64(page.Total))
+	}
+	p.header("bugdog_bugs_actionable", "gauge", "Bugs in the last scrape not muted by ignore rules.")
+	p.sample("bugdog_bugs_actionable", mergedLabels(), float64(stats.Actionable))
+	p.header("bugdog_bugs_muted", "gauge", "Bugs in the last scrape muted by ignore rules.")
+	p.sample("bugdog_bugs_muted", mergedLabels(), float64(stats.Muted))
+
+	p.header("bugdog_bugs_by_priority", "gauge", "Bugs in the last scrape by priority.")
+	for _, group := range countBugs(bugs, func(b Bug) string { return b.Priority }) {
+		p.sample("bugdog_bugs_by_priority", mergedLabels("priority", group.key), group.count)
+	}
+	p.header("bugdog_bugs_by_status", "gauge", "Bugs in the last scrape by status.")
+	for _, group := range countBugs(bugs, func(b Bug) string { return b.Status }) {
+		p.sample("bugdog_bugs_by_status", mergedLabels("status", group.key), group.count)
+	}
+
+	p.header("bugdog_monitoring_enabled", "gauge", "1 while the poller is running.")
+	p.sample("bugdog_monitoring_enabled", "", boolValue(health.Monitoring))
+	p.header("bugdog_last_success_timestamp_seconds", "gauge", "Unix time of the last successful scrape.")
+	if !health.LastSuccess.IsZero() {
+		p.sample("bugdog_last_success_timestamp_seconds", labels("target", target), float64(health.LastSuccess.Unix()))
+	}
+
+	x := m.metrics
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	p.header("bugdog_scrapes_total", "counter", "Scrapes attempted.")
+	for _, key := range sortedKeys(x.scrapes) {
+		p.sample("bugdog_scrapes_total", labels("target", key), x.scrapes[key])
+	}
+	p.header("bugdog_scrape_failures_total", "counter", "Failed scrapes by error kind.")
+	for _, key := range sortedPairs(x.failures) {
+		p.sample("bugdog_scrape_failures_total", labels("target", key[0], "kind", key[1]), x.failures[key])
+	}
+	p.header("bugdog_notifications_total", "counter", "Notifications by channel and result.")
+	for _, key := range sortedPairs(x.notifications) {
+		p.sample("bugdog_notifications_total", labels("channel", key[0], "result", key[1]), x.notifications[key])
+	}
+	p.header("bugdog_scrape_duration_seconds", "histogram", "Scrape latency.")
+	for _, key := range sortedKeys(x.durations) {
+		h := x.durations[key]
+		for i, bound := range scrapeDurationBuckets {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			p.sample("bugdog_scrape_duration_seconds_bucket", labels("target", key, "le", le), float64(h.counts[i]))
+		}
+		p.sample("bugdog_scrape_duration_seconds_bucket", labels("target", key, "le", "+Inf"), float64(h.count))
+		p.sample("bugdog_scrape_duration_seconds_sum", labels("target", key), h.sum)
+		p.sample("bugdog_scrape_duration_seconds_count", labels("target", key), float64(h.count))
+	}
+}
+
+// metricsTarget is the target label: the URL synced from, without secrets.
+func metricsTarget(cfg Config) string {
+	return MaskSecrets(syncTarget(cfg), cfg)
+}
+
+type bugGroup struct {
+	key   string
+	count float64
+}
+
+func countBugs(bugs []Bug, key func(Bug) string) []bugGroup {
+	counts := map[string]float64{}
+	for _, bug := range bugs {
+		value := key(bug)
+		if value == "" {
+			value = "unknown"
+		}
+		counts[value]++
+	}
+	groups := make([]bugGroup, 0, len(counts))
+	for _, value := range sortedKeys(counts) {
+		groups = append(groups, bugGroup{key: value, count: counts[value]})
+	}
+	return groups
+}
+
+type promWriter struct {
+	w io.Writer
+}
+
+func (p *promWriter) header(name, kind, help string) {
+	fmt.Fprintf(p.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) sample(name, labels string, value float64) {
+	fmt.Fprintf(p.w, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labels renders name/value pairs as a Prometheus label set.
+func labels(pairs ...string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedPairs(values map[[2]string]float64) [][2]string {
+	keys := make([][2]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
@@ -0,0 +1,773 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxLogEntries = 200
+
+const (
+	wakeCheckInterval = time.Minute
+	wakeJumpThreshold = 3 * wakeCheckInterval
+)
+
+const notifyTitle = "禅道监控"
+
+// Options configures a Monitor. The zero value keeps data under the user
+// config directory, delivers desktop notifications and emits no events.
+type Options struct {
+	DataDir    string
+	ConfigPath string
+	// Headless replaces desktop notifications with log entries.
+	Headless bool
+	// Logger, when set, receives every log entry as it is added.
+	Logger *log.Logger
+	// Sink receives the events a frontend renders; nil discards them.
+	Sink EventSink
+	// ReadOnly loads the data files as they are: no schema migrations,
+	// backup restores or secret moves are written back, and saves fail.
+	// One-shot commands use it so they never race a running instance.
+	ReadOnly bool
+}
+
+// Monitor is the polling, persistence and notification core. It knows
+// nothing about windows or trays; frontends follow it through an EventSink.
+type Monitor struct {
+	ctx               context.Context
+	mu                sync.Mutex
+	sink              EventSink
+	config            Config
+	stats             Stats
+	bugs              []Bug
+	lastAttempt       time.Time
+	lastSuccess       time.Time
+	lastError         string
+	lastErrorKind     string
+	snoozedUntil      time.Time
+	changeLog         []ChangeLogEntry
+	logEntries        []LogEntry
+	dataDir           string
+	configPath        string
+	pollerStop        chan struct{}
+	activeSync        *syncRun
+	pendingSync       *syncRun
+	monitoringEnabled bool
+	httpClient        *http.Client
+	secrets           secretStore
+	history           *historyStore
+	lockedFiles       map[string]error
+	headless          bool
+	readOnly          bool
+	logger            *log.Logger
+	api               *localServer
+	webhook           *localServer
+	webhookRejects    webhookRejects
+	metrics           *metrics
+	events            *eventHub
+	detailCache       map[int]cachedBugDetail
+	watched           map[int]WatchedBug
+	seen              map[int]seenBug
+	watchPolling      bool
+}
+
+func New(opts Options) *Monitor {
+	if opts.DataDir != "" {
+		_ = os.MkdirAll(opts.DataDir, 0o755)
+	}
+	return &Monitor{
+		sink:              opts.Sink,
+		config:            defaultConfig(),
+		dataDir:           opts.DataDir,
+		configPath:        opts.ConfigPath,
+		monitoringEnabled: true,
+		httpClient:        &http.Client{Timeout: 25 * time.Second},
+		headless:          opts.Headless,
+		readOnly:          opts.ReadOnly,
+		logger:            opts.Logger,
+		metrics:           newMetrics(),
+		events:            newEventHub(),
+	}
+}
+
+// Load reads the persisted config, state and change log without starting
+// the poller. Start calls it; one-shot commands use it on its own.
+func (m *Monitor) Load() {
+	_ = m.loadConfig()
+	_ = m.loadState()
+	_ = m.loadChangeLog()
+	_ = m.loadWatchlist()
+	_ = m.loadSeen()
+	historyDir := filepath.Join(m.ensureDataDir(), historyDirName)
+	if m.readOnly {
+		m.history = &historyStore{dir: historyDir}
+		return
+	}
+	m.history = newHistoryStore(historyDir)
+}
+
+// Start loads persisted data and starts the poller. ctx bounds every scrape;
+// cancelling it aborts the one in flight.
+func (m *Monitor) Start(ctx context.Context) {
+	m.ctx = ctx
+	m.Load()
+	m.startPolling()
+	m.startAPI()
+	m.startWebhook()
+}
+
+// Shutdown stops the poller and the HTTP listeners and waits up to timeout
+// for a running sync.
+func (m *Monitor) Shutdown(timeout time.Duration) bool {
+	m.stopPolling()
+	m.stopAPI()
+	m.stopWebhook()
+	return m.waitSyncIdle(timeout)
+}
+
+// DataDir returns the directory holding state, history and secrets.
+func (m *Monitor) DataDir() string {
+	return m.ensureDataDir()
+}
+
+// EmitAll re-sends every event, for a frontend that has just attached.
+func (m *Monitor) EmitAll() {
+	m.emitAll()
+}
+
+// GetConfig returns the config for the frontend, with secrets masked.
+func (m *Monitor) GetConfig() Config {
+	return maskConfig(m.currentConfig())
+}
+
+// CurrentConfig returns the config including secrets. Never hand it to a
+// frontend; use GetConfig there.
+func (m *Monitor) CurrentConfig() Config {
+	return m.currentConfig()
+}
+
+func (m *Monitor) currentConfig() Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.config
+}
+
+func (m *Monitor) SaveConfig(cfg Config) error {
+	previous := m.currentConfig()
+	cfg = sanitizeConfig(unmaskConfig(cfg, previous))
+	if err := validateIgnoreRules(cfg.IgnoreRules); err != nil {
+		return err
+	}
+	if cfg.SecretBackend != previous.SecretBackend {
+		m.mu.Lock()
+		from := m.secrets
+		m.mu.Unlock()
+		to := m.openSecretStore(cfg.SecretBackend)
+		if from != nil && from.Name() != to.Name() {
+			if err := m.switchSecretStore(from, to, cfg); err != nil {
+				return err
+			}
+		}
+		m.mu.Lock()
+		m.secrets = to
+		m.mu.Unlock()
+	}
+	m.mu.Lock()
+	m.config = cfg
+	m.mu.Unlock()
+	if err := m.saveConfig(cfg); err != nil {
+		return err
+	}
+	if m.isMonitoringEnabled() {
+		m.startPolling()
+	}
+	if apiConfigChanged(previous, cfg) {
+		m.startAPI()
+	}
+	if webhookConfigChanged(previous, cfg) {
+		m.startWebhook()
+	}
+	if ignoreRulesChanged(previous, cfg) {
+		m.reapplyIgnoreRules()
+	}
+	if detailSourceChanged(previous, cfg) {
+		m.clearDetailCache()
+	}
+	m.emitConfig()
+	if m.isMonitoringEnabled() {
+		go m.FetchNow()
+	}
+	return nil
+}
+
+func (m *Monitor) GetStats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// GetBugs returns the bugs parsed by the last successful scrape.
+func (m *Monitor) GetBugs() []Bug {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.bugs) == 0 {
+		return []Bug{}
+	}
+	return append([]Bug(nil), m.bugs...)
+}
+
+// GetHealth reports the outcome of the latest sync attempts. Status is
+// "ok", "error", or "unknown" before the first attempt; when it is "error",
+// ErrorKind says why (network, timeout, http, auth or other).
+func (m *Monitor) GetHealth() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health := Health{
+		Status:      "unknown",
+		Monitoring:  m.monitoringEnabled,
+		LastAttempt: m.lastAttempt,
+		LastSuccess: m.lastSuccess,
+		LastError:   m.lastError,
+		ErrorKind:   m.lastErrorKind,
+	}
+	if time.Now().Before(m.snoozedUntil) {
+		health.SnoozedUntil = m.snoozedUntil
+	}
+	switch {
+	case m.lastError != "":
+		health.Status = "error"
+	case !m.lastAttempt.IsZero():
+		health.Status = "ok"
+	}
+	return health
+}
+
+func (m *Monitor) GetChangeLog() []ChangeLogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.changeLog) == 0 {
+		return []ChangeLogEntry{}
+	}
+	return append([]ChangeLogEntry(nil), m.changeLog...)
+}
+
+func (m *Monitor) GetLogs() []LogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.logEntries) == 0 {
+		return []LogEntry{}
+	}
+	return append([]LogEntry(nil), m.logEntries...)
+}
+
+// FetchNow requests a sync and waits for the run that covers it. When a
+// scrape is already in flight the request is folded into a single follow-up
+// run that picks up the latest config.
+func (m *Monitor) FetchNow() error {
+	return m.requestSync().wait()
+}
+
+func (m *Monitor) runSync() error {
+	cfg := m.currentConfig()
+	if syncTarget(cfg) == "" {
+		return errors.New("missing URL")
+	}
+
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	started := time.Now()
+	var stats Stats
+	var bugs []Bug
+	var status int
+	var err error
+	if cfg.Source == sourceRelay {
+		m.addLog("info", fmt.Sprintf("Fetching from relay %s", cfg.RelayURL), 0)
+		stats, bugs, status, err = m.fetchRelay(ctx, cfg)
+	} else {
+		m.addLog("info", fmt.Sprintf("Scraping %s", cfg.URL), 0)
+		stats, bugs, status, err = m.scrape(ctx, cfg)
+	}
+	m.recordHistory(cfg, started, stats, status, err)
+	m.metrics.observeScrape(metricsTarget(cfg), time.Since(started), err)
+	m.mu.Lock()
+	wasFailing := m.lastError != ""
+	m.lastAttempt = started
+	if err != nil {
+		m.lastError = MaskSecrets(err.Error(), m.config)
+		m.lastErrorKind = failureKind(err)
+	} else {
+		m.lastSuccess = started
+		m.lastError = ""
+		m.lastErrorKind = ""
+	}
+	lastError := m.lastError
+	m.mu.Unlock()
+	m.emitHealth()
+	if err != nil {
+		m.addLog("error", fmt.Sprintf("Scrape failed: %v", err), status)
+		if !wasFailing {
+			m.runHooks(HookEvent{Type: HookEventHealth, Health: "error", Error: lastError})
+		}
+		return err
+	}
+	m.addLog("info", fmt.Sprintf("HTTP %d - parsed %d bugs", status, stats.Total), status)
+	stats, bugs = applyIgnoreRules(stats, bugs, cfg.IgnoreRules, started)
+	if wasFailing {
+		m.runHooks(HookEvent{Type: HookEventHealth, Health: "ok", Total: stats.Actionable, Severity: stats.ActionableSeverity})
+	}
+
+	var previous Stats
+	var previousBugs []Bug
+	var notify bool
+	var totalChanged bool
+	var delta int
+	var actionableDelta int
+	m.mu.Lock()
+	previous = m.stats
+	previousBugs = m.bugs
+	m.trackSeen(bugs, started)
+	stats, bugs = m.flagUnread(stats, bugs)
+	m.stats = stats
+	m.bugs = bugs
+	hasPrev := !previous.LastUpdated.IsZero()
+	totalChanged = hasPrev && previous.Total != stats.Total
+	if totalChanged {
+		delta = stats.Total - previous.Total
+	}
+	// Muted bugs count in the change log but never notify or run hooks.
+	if hasPrev {
+		actionableDelta = stats.Actionable - previous.Actionable
+	}
+	notify = hasPrev &&
+		actionableDelta != 0 &&
+		shouldNotifyOnDelta(actionableDelta, cfg.NotifyOnIncrease, cfg.NotifyOnDecrease) &&
+		selectedLevelsChanged(previous.ActionableSeverity, stats.ActionableSeverity, cfg.NotifyLevels)
+	m.mu.Unlock()
+
+	_ = m.saveState(stats, bugs)
+	_ = m.saveSeen()
+	m.emitStats()
+
+	var entry *ChangeLogEntry
+	if totalChanged {
+		entry = &ChangeLogEntry{
+			Timestamp: stats.LastUpdated,
+			Kind:      ChangeKindStats,
+			Total:     stats.Total,
+			Delta:     delta,
+			Severity:  stats.Severity,
+		}
+		m.addChangeLog(*entry)
+		m.emitChangeLog()
+	}
+	if actionableDelta != 0 {
+		m.runHooks(HookEvent{
+			Type:     HookEventChange,
+			Total:    stats.Actionable,
+			Delta:    actionableDelta,
+			Severity: stats.ActionableSeverity,
+			Change:   entry,
+		})
+	}
+	if notify {
+		message := buildNotifyMessage(previous.ActionableSeverity, stats.ActionableSeverity, cfg.NotifyLevels, stats.Actionable)
+		if stats.Muted > 0 {
+			message += fmt.Sprintf("（另有 %d 个已忽略）", stats.Muted)
+		}
+		var added []Bug
+		// Without the previous list every bug would look new.
+		if len(previousBugs) > 0 || previous.Total == 0 {
+			added = newSevereBugs(previousBugs, bugs)
+		}
+		m.maybeNotifyChange(message, added)
+	}
+	return nil
+}
+
+// GetHistory returns scrape history between from and to aggregated into
+// "hour", "day" or "week" buckets for the trend charts.
+func (m *Monitor) GetHistory(from, to time.Time, bucket string) ([]HistoryPoint, error) {
+	if m.history == nil {
+		return []HistoryPoint{}, nil
+	}
+	return m.history.Query(from, to, bucket)
+}
+
+// HistoryRecords returns the raw history samples between from and to.
+func (m *Monitor) HistoryRecords(from, to time.Time) ([]HistoryRecord, error) {
+	if m.history == nil {
+		return []HistoryRecord{}, nil
+	}
+	return m.history.Records(from, to)
+}
+
+func (m *Monitor) recordHistory(cfg Config, started time.Time, stats Stats, status int, scrapeErr error) {
+	if m.history == nil {
+		return
+	}
+	record := HistoryRecord{
+		Timestamp:  started,
+		Status:     historyStatusOK,
+		HTTPStatus: status,
+		DurationMs: time.Since(started).Milliseconds(),
+		Total:      stats.Total,
+		Severity:   stats.Severity,
+	}
+	if scrapeErr != nil {
+		record.Status = historyStatusError
+		record.Error = MaskSecrets(scrapeErr.Error(), cfg)
+	}
+	if err := m.history.Append(record); err != nil {
+		m.addLog("error", fmt.Sprintf("History write failed: %v", err), 0)
+	}
+}
+
+func (m *Monitor) TestNotification() error {
+	m.sendNotification(notifyTitle, "测试通知：系统通知与声音已触发。")
+	m.runNotificationHooks(notifyTitle, "测试通知：系统通知与声音已触发。")
+	m.playSound(true)
+	m.addLog("info", "Test notification triggered", 0)
+	m.emitLogs()
+	return nil
+}
+
+func (m *Monitor) ClearChangeLog() error {
+	m.mu.Lock()
+	m.changeLog = nil
+	m.mu.Unlock()
+	m.removeVersioned(changeLogSchema)
+	m.emitChangeLog()
+	return nil
+}
+
+func (m *Monitor) ClearMonitoringData() error {
+	m.mu.Lock()
+	m.stats = Stats{}
+	m.bugs = nil
+	m.changeLog = nil
+	m.logEntries = nil
+	m.watched = nil
+	m.seen = nil
+	m.mu.Unlock()
+	m.emitAll()
+	m.removeVersioned(changeLogSchema)
+	m.removeVersioned(stateSchema)
+	m.removeVersioned(watchSchema)
+	m.removeVersioned(seenSchema)
+	return nil
+}
+
+func (m *Monitor) startPolling() {
+	m.stopPolling()
+	if !m.isMonitoringEnabled() {
+		return
+	}
+	cfg := m.currentConfig()
+	intervalMinutes := cfg.IntervalMinutes
+	interval := time.Duration(intervalMinutes) * time.Minute
+	if interval <= 0 {
+		intervalMinutes = 15
+		interval = 15 * time.Minute
+	}
+	stop := make(chan struct{})
+	m.mu.Lock()
+	m.pollerStop = stop
+	m.mu.Unlock()
+	m.addLog("info", fmt.Sprintf("Polling started: every %d minutes", intervalMinutes), 0)
+	go m.pollLoop(stop, interval, intervalMinutes, syncTarget(cfg))
+	if cfg.Source == sourceRelay && cfg.RelayURL != "" {
+		go m.relayLoop(stop, cfg)
+	}
+}
+
+// pollLoop runs the interval ticker alongside a short watchdog tick. The
+// watchdog compares wall-clock time (monotonic readings stop during suspend
+// on some platforms) to spot a resume from sleep, and probes the target host
+// to spot the network coming back; either one triggers an immediate sync.
+func (m *Monitor) pollLoop(stop chan struct{}, interval time.Duration, intervalMinutes int, target string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	watchdog := time.NewTicker(wakeCheckInterval)
+	defer watchdog.Stop()
+
+	lastCheck := time.Now().Round(0)
+	online := true
+	for {
+		select {
+		case <-ticker.C:
+			m.addLog("info", fmt.Sprintf("Auto sync triggered (every %d minutes)", intervalMinutes), 0)
+			_ = m.FetchNow()
+			// A slow sync must not look like a clock jump to the watchdog.
+			lastCheck = time.Now().Round(0)
+		case <-watchdog.C:
+			now := time.Now().Round(0)
+			elapsed := now.Sub(lastCheck)
+			lastCheck = now
+
+			reachable := probeHost(context.Background(), target)
+			reconnected := reachable && !online
+			if !reachable && online {
+				m.addLog("warn", "Network probe failed: target host unreachable", 0)
+			}
+			online = reachable
+
+			var reason string
+			switch {
+			case elapsed > wakeJumpThreshold:
+				reason = fmt.Sprintf("Wake from sleep detected (clock jumped %s)", elapsed.Round(time.Second))
+			case reconnected:
+				reason = "Network reconnected"
+			default:
+				continue
+			}
+			m.addLog("info", reason+", syncing now", 0)
+			ticker.Reset(interval)
+			_ = m.FetchNow()
+			lastCheck = time.Now().Round(0)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (m *Monitor) stopPolling() {
+	m.mu.Lock()
+	stop := m.pollerStop
+	m.pollerStop = nil
+	m.mu.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+}
+
+// Log adds a line to the activity log on behalf of the embedding app.
+func (m *Monitor) Log(level, message string) {
+	m.addLog(level, message, 0)
+}
+
+func (m *Monitor) addLog(level, message string, status int) {
+	m.addLogEntry(LogEntry{Level: level, Status: status, Message: message})
+}
+
+// addLogEntry stamps, masks and stores entry, then echoes it to the logger.
+func (m *Monitor) addLogEntry(entry LogEntry) {
+	m.mu.Lock()
+	entry.Timestamp = time.Now()
+	entry.Message = MaskSecrets(entry.Message, m.config)
+	entry.Output = MaskSecrets(entry.Output, m.config)
+	m.logEntries = append([]LogEntry{entry}, m.logEntries...)
+	if len(m.logEntries) > maxLogEntries {
+		m.logEntries = m.logEntries[:maxLogEntries]
+	}
+	logger := m.logger
+	m.mu.Unlock()
+	if logger != nil {
+		if entry.Status != 0 {
+			logger.Printf("[%s] %s (status %d)", entry.Level, entry.Message, entry.Status)
+		} else {
+			logger.Printf("[%s] %s", entry.Level, entry.Message)
+		}
+		if entry.Output != "" {
+			logger.Print(entry.Output)
+		}
+	}
+	m.emitLogs()
+}
+
+func (m *Monitor) addChangeLog(entry ChangeLogEntry) {
+	m.mu.Lock()
+	m.changeLog = append([]ChangeLogEntry{entry}, m.changeLog...)
+	if len(m.changeLog) > 200 {
+		m.changeLog = m.changeLog[:200]
+	}
+	entries := append([]ChangeLogEntry(nil), m.changeLog...)
+	m.mu.Unlock()
+	_ = m.saveChangeLog(entries)
+}
+
+func (m *Monitor) emitAll() {
+	m.emitConfig()
+	m.emitStats()
+	m.emitChangeLog()
+	m.emitLogs()
+	m.emitMonitoring()
+	m.emitHealth()
+	m.emitWatchlist()
+}
+
+func (m *Monitor) emitConfig() {
+	m.emit(EventConfig, m.GetConfig())
+}
+
+func (m *Monitor) emitStats() {
+	m.emit(EventStats, m.GetStats())
+}
+
+func (m *Monitor) emitChangeLog() {
+	m.emit(EventChangeLog, m.GetChangeLog())
+}
+
+func (m *Monitor) emitLogs() {
+	m.emit(EventLogs, m.GetLogs())
+}
+
+func (m *Monitor) emitHealth() {
+	m.emit(EventHealth, m.GetHealth())
+}
+
+func (m *Monitor) emitWatchlist() {
+	m.emit(EventWatchlist, m.GetWatchedBugs())
+}
+
+func (m *Monitor) emitMonitoring() {
+	m.emit(EventMonitoring, m.isMonitoringEnabled())
+}
+
+// maybeNotifyChange sends a change notification and runs the notification
+// hooks. The steps of newBugs are only fetched when the notification will
+// actually be shown or handed to a hook.
+func (m *Monitor) maybeNotifyChange(message string, newBugs []Bug) {
+	if until := m.GetHealth().SnoozedUntil; !until.IsZero() {
+		m.addLog("info", fmt.Sprintf("Notification suppressed: snoozed until %s", until.Format("01-02 15:04")), 0)
+		return
+	}
+	cfg := m.currentConfig()
+	hooks := m.hasHooks(HookEventNotification)
+	if cfg.EnableNotifications || hooks {
+		if previews := m.bugPreviews(newBugs); previews != "" {
+			message += "\n" + previews
+		}
+	}
+	if cfg.EnableNotifications {
+		m.sendNotification(notifyTitle, message)
+	}
+	if hooks {
+		m.runNotificationHooks(notifyTitle, message)
+	}
+	if cfg.EnableSound {
+		m.playSound(false)
+	}
+}
+
+func (m *Monitor) playSound(force bool) {
+	m.emit(EventPlaySound, force)
+}
+
+func (m *Monitor) emit(name string, payload any) {
+	m.events.Emit(name, payload)
+	if m.sink == nil {
+		return
+	}
+	m.sink.Emit(name, payload)
+}
+
+func shouldNotifyOnDelta(delta int, onIncrease, onDecrease bool) bool {
+	if delta > 0 {
+		return onIncrease
+	}
+	if delta < 0 {
+		return onDecrease
+	}
+	return false
+}
+
+func selectedLevelsChanged(prev SeverityCounts, curr SeverityCounts, levels map[string]bool) bool {
+	if levels == nil || len(levels) == 0 {
+		levels = defaultNotifyLevels()
+	}
+	if levels["level1"] && prev.Critical != curr.Critical {
+		return true
+	}
+	if levels["level2"] && prev.Severe != curr.Severe {
+		return true
+	}
+	if levels["level3"] && prev.Major != curr.Major {
+		return true
+	}
+	if levels["level4"] && prev.Minor != curr.Minor {
+		return true
+	}
+	return false
+}
+
+func buildNotifyMessage(prev SeverityCounts, curr SeverityCounts, levels map[string]bool, total int) string {
+	if levels == nil || len(levels) == 0 {
+		levels = defaultNotifyLevels()
+	}
+	parts := make([]string, 0, 4)
+	if levels["level1"] && prev.Critical != curr.Critical {
+		parts = append(parts, fmt.Sprintf("一级 %d→%d", prev.Critical, curr.Critical))
+	}
+	if levels["level2"] && prev.Severe != curr.Severe {
+		parts = append(parts, fmt.Sprintf("二级 %d→%d", prev.Severe, curr.Severe))
+	}
+	if levels["level3"] && prev.Major != curr.Major {
+		parts = append(parts, fmt.Sprintf("三级 %d→%d", prev.Major, curr.Major))
+	}
+	if levels["level4"] && prev.Minor != curr.Minor {
+		parts = append(parts, fmt.Sprintf("四级 %d→%d", prev.Minor, curr.Minor))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("选中等级数量变化，当前总数 %d", total)
+	}
+	return fmt.Sprintf("等级变化：%s，当前总数 %d", strings.Join(parts, "，"), total)
+}
+
+// SnoozeNotifications silences change notifications and sounds until the
+// given time while polling carries on. A zero time ends the snooze.
+func (m *Monitor) SnoozeNotifications(until time.Time) {
+	m.mu.Lock()
+	m.snoozedUntil = until
+	m.mu.Unlock()
+	if until.IsZero() {
+		m.addLog("info", "Notification snooze cleared", 0)
+	} else {
+		m.addLog("info", fmt.Sprintf("Notifications snoozed until %s", until.Format("01-02 15:04")), 0)
+	}
+	m.emitHealth()
+}
+
+// IsMonitoring reports whether the poller is enabled.
+func (m *Monitor) IsMonitoring() bool {
+	return m.isMonitoringEnabled()
+}
+
+// SetMonitoring pauses or resumes the poller.
+func (m *Monitor) SetMonitoring(enabled bool) {
+	m.setMonitoringEnabled(enabled)
+}
+
+func (m *Monitor) setMonitoringEnabled(enabled bool) {
+	m.mu.Lock()
+	if m.monitoringEnabled == enabled {
+		m.mu.Unlock()
+		return
+	}
+	m.monitoringEnabled = enabled
+	m.mu.Unlock()
+
+	if enabled {
+		m.addLog("info", "Monitoring resumed", 0)
+		m.startPolling()
+	} else {
+		m.stopPolling()
+		m.addLog("info", "Monitoring paused", 0)
+	}
+	m.emitMonitoring()
+}
+
+func (m *Monitor) isMonitoringEnabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.monitoringEnabled
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestMonitor returns a headless monitor that scrapes url and records
+// its events.
+func newTestMonitor(t *testing.T, dir, url string) (*Monitor, *RecordingSink) {
+	t.Helper()
+	sink := &RecordingSink{}
+	m := New(Options{DataDir: dir, Headless: true, Sink: sink})
+	m.config.URL = url
+	m.config.Cookie = "zentaosid=fake"
+	m.config.EnableSound = false
+	return m, sink
+}
+
+// lastPayload returns the payload of the newest event called name.
+func lastPayload[T any](t *testing.T, sink *RecordingSink, name string) T {
+	t.Helper()
+	events := sink.Named(name)
+	if len(events) == 0 {
+		t.Fatalf("no %q event emitted", name)
+	}
+	payload, ok := events[len(events)-1].Payload.(T)
+	if !ok {
+		t.Fatalf("%q payload is %T", name, events[len(events)-1].Payload)
+	}
+	return payload
+}
+
+func bugIDs(bugs []Bug) []int {
+	ids := make([]int, 0, len(bugs))
+	for _, bug := range bugs {
+		ids = append(ids, bug.ID)
+	}
+	return ids
+}
+
+func TestFetchNowEmitsAndPersists(t *testing.T) {
+	z := newFakeZenTao(t)
+	dir := t.TempDir()
+	url := z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 11, Severity: 2, Title: "导出报表超时", Status: "激活"},
+		fakeBug{ID: 12, Severity: 4, Title: "提示语有错别字", Status: "激活"},
+	))
+	m, sink := newTestMonitor(t, dir, url)
+
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+	stats := lastPayload[Stats](t, sink, EventStats)
+	if stats.Total != 2 || stats.Severity != (SeverityCounts{Severe: 1, Minor: 1}) || stats.LastUpdated.IsZero() {
+		t.Errorf("stats event = %+v, want 2 bugs, one severe and one minor", stats)
+	}
+	if health := lastPayload[Health](t, sink, EventHealth); health.Status != "ok" || health.LastSuccess.IsZero() {
+		t.Errorf("health event = %+v, want ok", health)
+	}
+	if ids := bugIDs(m.GetBugs()); !reflect.DeepEqual(ids, []int{11, 12}) {
+		t.Errorf("bugs = %v, want [11 12]", ids)
+	}
+	if got := z.cookies[len(z.cookies)-1]; got != "zentaosid=fake" {
+		t.Errorf("request cookie = %q, want the configured one", got)
+	}
+	if len(sink.Named(EventChangeLog)) != 0 {
+		t.Error("the first sync wrote a change log entry")
+	}
+
+	z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 10, Severity: 1, Title: "登录页白屏", Status: "激活"},
+		fakeBug{ID: 11, Severity: 2, Title: "导出报表超时", Status: "激活"},
+		fakeBug{ID: 12, Severity: 4, Title: "提示语有错别字", Status: "激活"},
+	))
+	sink.Reset()
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := lastPayload[Stats](t, sink, EventStats); stats.Total != 3 || stats.Severity.Critical != 1 {
+		t.Errorf("stats event after the new bug = %+v", stats)
+	}
+	changes := lastPayload[[]ChangeLogEntry](t, sink, EventChangeLog)
+	if len(changes) != 1 || changes[0].Kind != ChangeKindStats || changes[0].Delta != 1 || changes[0].Total != 3 {
+		t.Errorf("change log = %+v, want one +1 entry", changes)
+	}
+	notified := false
+	for _, entry := range m.GetLogs() {
+		notified = notified || entry.Level == "notify" && strings.Contains(entry.Message, "一级 0→1")
+	}
+	if !notified {
+		t.Error("no notification for the new critical bug")
+	}
+
+	reloaded := New(Options{DataDir: dir, Headless: true})
+	reloaded.Load()
+	if got := reloaded.GetStats(); got.Total != 3 || !got.LastUpdated.Equal(m.GetStats().LastUpdated) {
+		t.Errorf("persisted stats = %+v, want the last sync", got)
+	}
+	if ids := bugIDs(reloaded.GetBugs()); !reflect.DeepEqual(ids, []int{10, 11, 12}) {
+		t.Errorf("persisted bugs = %v, want [10 11 12]", ids)
+	}
+	if got := reloaded.GetChangeLog(); len(got) != 1 || got[0].Delta != 1 {
+		t.Errorf("persisted change log = %+v", got)
+	}
+}
+
+func TestFetchNowLoginRequired(t *testing.T) {
+	z := newFakeZenTao(t)
+	dir := t.TempDir()
+	url := z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 21, Severity: 3, Title: "按钮错位", Status: "激活"},
+	))
+	m, sink := newTestMonitor(t, dir, url)
+	if err := m.FetchNow(); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(m.dataPath(stateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	z.serve("/zentao/my-work-bug.html", loginPage)
+	sink.Reset()
+	if err := m.FetchNow(); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("FetchNow with an expired session = %v, want ErrLoginRequired", err)
+	}
+	health := lastPayload[Health](t, sink, EventHealth)
+	if health.Status != "error" || health.ErrorKind != failureAuth || health.LastError == "" {
+		t.Errorf("health event = %+v, want an auth error", health)
+	}
+	if strings.Contains(health.LastError, "zentaosid=fake") {
+		t.Errorf("health error leaks the cookie: %s", health.LastError)
+	}
+	if events := sink.Named(EventStats); len(events) != 0 {
+		t.Errorf("failed sync emitted stats: %+v", events)
+	}
+	if got := m.GetStats(); got.Total != 1 {
+		t.Errorf("stats after the failed sync = %+v, want the last good one", got)
+	}
+	current, err := os.ReadFile(m.dataPath(stateFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(current) != string(saved) {
+		t.Error("failed sync rewrote state.json")
+	}
+}
@@ -0,0 +1,33 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"time"
+)
+
+const probeTimeout = 5 * time.Second
+
+// probeHost reports whether a TCP connection to the host behind rawURL can be
+// opened. It is a cheap reachability check, not an HTTP request.
+func probeHost(ctx context.Context, rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return false
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "80"
+		if parsed.Scheme == "https" {
+			port = "443"
+		}
+	}
+	dialer := net.Dialer{Timeout: probeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(parsed.Hostname(), port))
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,58 @@
+package monitor
+
+import (
+	"fmt"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Notifier is one delivery channel for change notifications.
+type Notifier interface {
+	Name() string
+	Notify(title, message string) error
+}
+
+type desktopNotifier struct{}
+
+func (desktopNotifier) Name() string {
+	return "desktop"
+}
+
+func (desktopNotifier) Notify(title, message string) error {
+	return beeep.Notify(title, message, "")
+}
+
+// logNotifier writes notifications to the app log. Headless mode uses it in
+// place of desktop popups, which need a session bus or a shell.
+type logNotifier struct {
+	monitor *Monitor
+}
+
+func (logNotifier) Name() string {
+	return "log"
+}
+
+func (n logNotifier) Notify(title, message string) error {
+	n.monitor.addLog("notify", fmt.Sprintf("%s: %s", title, message), 0)
+	return nil
+}
+
+// Notifiers returns the channels notifications are currently sent through.
+func (m *Monitor) Notifiers() []Notifier {
+	if m.headless {
+		return []Notifier{logNotifier{monitor: m}}
+	}
+	return []Notifier{desktopNotifier{}}
+}
+
+// sendNotification shows a notification through Notifiers. Notification
+// hooks are run separately, by runNotificationHooks.
+func (m *Monitor) sendNotification(title, message string) {
+	for _, channel := range m.Notifiers() {
+		err := channel.Notify(title, message)
+		m.metrics.observeNotification(channel.Name(), err)
+		if err != nil {
+			m.addLog("error", fmt.Sprintf("Notification via %s failed: %v", channel.Name(), err), 0)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sources a Monitor can take its data from.
+const (
+	sourceScrape = "scrape"
+	sourceRelay  = "relay"
+)
+
+const (
+	relayRetryMin = 5 * time.Second
+	relayRetryMax = time.Minute
+)
+
+// errRelayEmpty means the relay answered but has not completed a scrape yet.
+var errRelayEmpty = errors.New("relay has no data yet")
+
+// syncTarget is the URL a sync talks to: the relay in relay mode, otherwise
+// the ZenTao page.
+func syncTarget(cfg Config) string {
+	if cfg.Source == sourceRelay {
+		return cfg.RelayURL
+	}
+	return cfg.URL
+}
+
+// fetchRelay reads the stats and bug list another instance published on its
+// local API, in place of scraping ZenTao.
+func (m *Monitor) fetchRelay(ctx context.Context, cfg Config) (Stats, []Bug, int, error) {
+	var stats Stats
+	status, err := m.getRelayJSON(ctx, cfg, "/stats", &stats)
+	if err != nil {
+		return Stats{}, nil, status, err
+	}
+	if stats.LastUpdated.IsZero() {
+		return Stats{}, nil, status, errRelayEmpty
+	}
+	var bugs []Bug
+	if status, err = m.getRelayJSON(ctx, cfg, "/bugs", &bugs); err != nil {
+		return Stats{}, nil, status, err
+	}
+	return stats, bugs, status, nil
+}
+
+func (m *Monitor) getRelayJSON(ctx context.Context, cfg Config, path string, out any) (int, error) {
+	req, err := newRelayRequest(ctx, cfg, path)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, &HTTPStatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decoding relay %s: %w", path, err)
+	}
+	return resp.StatusCode, nil
+}
+
+func newRelayRequest(ctx context.Context, cfg Config, path string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.RelayURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.RelayToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.RelayToken)
+	}
+	return req, nil
+}
+
+// relayLoop follows the relay's event stream until stop is closed and syncs
+// whenever the relay publishes new stats, so subscribers see changes as soon
+// as the relay does rather than on their own interval. Dropped connections
+// are retried with backoff.
+func (m *Monitor) relayLoop(stop chan struct{}, cfg Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	retry := relayRetryMin
+	for {
+		connected, err := m.followRelay(ctx, cfg)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			retry = relayRetryMin
+		}
+		m.addLog("warn", fmt.Sprintf("Relay stream lost: %v; retrying in %s", err, retry), 0)
+		select {
+		case <-time.After(retry):
+		case <-ctx.Done():
+			return
+		}
+		retry *= 2
+		if retry > relayRetryMax {
+			retry = relayRetryMax
+		}
+	}
+}
+
+// followRelay reads one /events connection until it ends. connected reports
+// whether the relay accepted the stream.
+func (m *Monitor) followRelay(ctx context.Context, cfg Config) (connected bool, err error) {
+	req, err := newRelayRequest(ctx, cfg, "/events")
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	// The shared client's timeout would cut the stream off.
+	client := &http.Client{Transport: m.httpClient.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return false, &HTTPStatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	m.addLog("info", fmt.Sprintf("Following relay %s", cfg.RelayURL), resp.StatusCode)
+
+	// Every stream opens with a snapshot of the relay's state; its stats
+	// event doubles as the catch-up sync after a reconnect.
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == EventStats {
+				m.TriggerSync()
+			}
+			event = ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return true, err
+	}
+	return true, errors.New("stream closed by relay")
+}
@@ -0,0 +1,284 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const schemaVersionKey = "schemaVersion"
+
+var errSchemaTooNew = errors.New("written by a newer version of the app")
+
+// migration upgrades a decoded document by exactly one schema version.
+type migration func(doc any) (any, error)
+
+// fileSchema describes one persisted file. Its current version is the number
+// of migrations: a file at version N is brought up to date by running
+// migrations[N:] in order. Files written before versioning count as version 0.
+type fileSchema struct {
+	name       string
+	migrations []migration
+}
+
+func (s fileSchema) version() int {
+	return len(s.migrations)
+}
+
+var (
+	configSchema = fileSchema{
+		name: configFileName,
+		migrations: []migration{
+			migrateConfigV0,
+			migrateConfigV1,
+		},
+	}
+	stateSchema = fileSchema{
+		name: stateFileName,
+		migrations: []migration{
+			stampVersion,
+			migrateStateV1,
+		},
+	}
+	changeLogSchema = fileSchema{
+		name: changeLogFileName,
+		migrations: []migration{
+			migrateChangeLogV0,
+			migrateChangeLogV1,
+		},
+	}
+)
+
+// migrateConfigV0 fills in the notification switches that v0 files may lack
+// because they were added after the first release.
+func migrateConfigV0(doc any) (any, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("config is not an object")
+	}
+	levels, _ := obj["notifyLevels"].(map[string]any)
+	if levels == nil {
+		levels = map[string]any{}
+	}
+	for key, value := range defaultNotifyLevels() {
+		if _, ok := levels[key]; !ok {
+			levels[key] = value
+		}
+	}
+	obj["notifyLevels"] = levels
+	for _, key := range []string{"notifyOnIncrease", "notifyOnDecrease"} {
+		if _, ok := obj[key]; !ok {
+			obj[key] = true
+		}
+	}
+	return obj, nil
+}
+
+// migrateConfigV1 drops the old all-interfaces webhook address that earlier
+// versions saved by default, so configs that never enabled the receiver
+// pick up the localhost default. An enabled receiver keeps its address.
+func migrateConfigV1(doc any) (any, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("config is not an object")
+	}
+	if enabled, _ := obj["webhookEnabled"].(bool); !enabled && obj["webhookAddr"] == legacyWebhookAddr {
+		delete(obj, "webhookAddr")
+	}
+	return obj, nil
+}
+
+// migrateChangeLogV0 wraps the bare v0 array in an object so the file can
+// carry a version.
+func migrateChangeLogV0(doc any) (any, error) {
+	switch value := doc.(type) {
+	case nil:
+		return map[string]any{"entries": []any{}}, nil
+	case []any:
+		return map[string]any{"entries": value}, nil
+	case map[string]any:
+		return value, nil
+	default:
+		return nil, errors.New("changelog is neither an array nor an object")
+	}
+}
+
+// migrateChangeLogV1 marks the existing entries, which all describe list
+// stats, now that watched bugs log entries too.
+func migrateChangeLogV1(doc any) (any, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("changelog is not an object")
+	}
+	entries, _ := obj["entries"].([]any)
+	for _, raw := range entries {
+		if entry, ok := raw.(map[string]any); ok {
+			if _, ok := entry["kind"]; !ok {
+				entry["kind"] = ChangeKindStats
+			}
+		}
+	}
+	return obj, nil
+}
+
+// migrateStateV1 fills in the actionable counts added with ignore rules.
+// Nothing was muted before them, so they equal the raw counts; leaving them
+// zero would read as a drop and notify on the first sync after upgrading.
+func migrateStateV1(doc any) (any, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, errors.New("state is not an object")
+	}
+	stats, _ := obj["lastStats"].(map[string]any)
+	if stats == nil {
+		return obj, nil
+	}
+	if _, ok := stats["actionable"]; !ok {
+		stats["actionable"] = stats["total"]
+		stats["actionableSeverity"] = stats["severity"]
+		stats["muted"] = 0
+	}
+	return obj, nil
+}
+
+func stampVersion(doc any) (any, error) {
+	if _, ok := doc.(map[string]any); !ok {
+		return nil, errors.New("document is not an object")
+	}
+	return doc, nil
+}
+
+func schemaVersionOf(doc any) (int, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return 0, nil
+	}
+	raw, ok := obj[schemaVersionKey]
+	if !ok {
+		return 0, nil
+	}
+	number, ok := raw.(float64)
+	if !ok || number < 0 || number != float64(int(number)) {
+		return 0, fmt.Errorf("invalid %s %v", schemaVersionKey, raw)
+	}
+	return int(number), nil
+}
+
+// decodeVersioned migrates data to the schema's current version and decodes
+// it into target. It reports the version the data was stored at.
+func decodeVersioned(data []byte, schema fileSchema, target any) (int, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+	version, err := schemaVersionOf(doc)
+	if err != nil {
+		return 0, err
+	}
+	if version > schema.version() {
+		return version, fmt.Errorf("%s schema v%d %w (supported up to v%d)", schema.name, version, errSchemaTooNew, schema.version())
+	}
+	for _, migrate := range schema.migrations[version:] {
+		if doc, err = migrate(doc); err != nil {
+			return version, fmt.Errorf("migrating %s from v%d: %w", schema.name, version, err)
+		}
+	}
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return version, err
+	}
+	return version, json.Unmarshal(migrated, target)
+}
+
+// encodeVersioned stamps payload, which must marshal to a JSON object, with
+// the schema's current version.
+func encodeVersioned(schema fileSchema, payload any) (map[string]any, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	obj[schemaVersionKey] = schema.version()
+	return obj, nil
+}
+
+// readVersioned loads a data file, upgrading it on disk when it was stored
+// at an older schema version. A file from a newer version is left untouched
+// and locked against writes for the rest of the session.
+func (m *Monitor) readVersioned(schema fileSchema, target any) error {
+	path := m.dataPath(schema.name)
+	var storedVersion int
+	err := m.readDataFile(path, func(data []byte) error {
+		version, err := decodeVersioned(data, schema, target)
+		storedVersion = version
+		if errors.Is(err, errSchemaTooNew) {
+			return errKeepFile{err}
+		}
+		return err
+	})
+	var keep errKeepFile
+	if errors.As(err, &keep) {
+		m.mu.Lock()
+		if m.lockedFiles == nil {
+			m.lockedFiles = map[string]error{}
+		}
+		m.lockedFiles[schema.name] = keep.err
+		m.mu.Unlock()
+		m.addLog("error", fmt.Sprintf("Refusing to load %v; the file will not be overwritten", keep.err), 0)
+		return keep.err
+	}
+	if err != nil {
+		return err
+	}
+	if storedVersion < schema.version() && !m.readOnly {
+		if err := m.writeVersioned(schema, target); err != nil {
+			m.addLog("error", fmt.Sprintf("Saving migrated %s failed: %v", schema.name, err), 0)
+		} else {
+			m.addLog("info", fmt.Sprintf("Migrated %s from schema v%d to v%d", schema.name, storedVersion, schema.version()), 0)
+		}
+	}
+	return nil
+}
+
+func (m *Monitor) writeVersioned(schema fileSchema, payload any) error {
+	if m.readOnly {
+		return fmt.Errorf("not saving %s: %w", schema.name, errReadOnly)
+	}
+	m.mu.Lock()
+	lockErr := m.lockedFiles[schema.name]
+	m.mu.Unlock()
+	if lockErr != nil {
+		return fmt.Errorf("not overwriting %s: %w", schema.name, lockErr)
+	}
+	doc, err := encodeVersioned(schema, payload)
+	if err != nil {
+		return err
+	}
+	return writeJSON(m.dataPath(schema.name), doc)
+}
+
+func (m *Monitor) removeVersioned(schema fileSchema) {
+	m.mu.Lock()
+	locked := m.lockedFiles[schema.name] != nil
+	m.mu.Unlock()
+	if locked {
+		return
+	}
+	removeJSON(m.dataPath(schema.name))
+}
+
+// errKeepFile marks a decode failure that must not be treated as corruption.
+type errKeepFile struct {
+	err error
+}
+
+func (e errKeepFile) Error() string {
+	return e.err.Error()
+}
+
+func (e errKeepFile) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,311 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// installFixture copies testdata/fixture into m's data directory as name.
+func installFixture(t *testing.T, m *Monitor, fixture, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := m.dataPath(name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// storedVersion reads back the schemaVersion stamped into path.
+func storedVersion(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	version, err := schemaVersionOf(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestConfigMigrations(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    Config
+	}{
+		{
+			fixture: "config.v0.json",
+			want: Config{
+				URL:                 "http://zentao.example.com/my-work-bug.html",
+				IntervalMinutes:     10,
+				EnableNotifications: true,
+				NotifyLevels:        map[string]bool{"level1": true, "level2": false, "level3": true, "level4": true},
+				NotifyOnIncrease:    true,
+				NotifyOnDecrease:    true,
+			},
+		},
+		{
+			fixture: "config.v1.json",
+			want: Config{
+				URL:              "http://zentao.example.com/my-work-bug.html",
+				IntervalMinutes:  30,
+				EnableSound:      true,
+				NotifyLevels:     map[string]bool{"level1": true, "level2": true, "level3": false, "level4": false},
+				NotifyOnIncrease: true,
+			},
+		},
+		{
+			fixture: "config.v1-webhook.json",
+			want: Config{
+				URL:                 "http://zentao.example.com/my-work-bug.html",
+				IntervalMinutes:     15,
+				EnableNotifications: true,
+				EnableSound:         true,
+				NotifyLevels:        map[string]bool{"level1": true, "level2": true, "level3": true, "level4": true},
+				NotifyOnIncrease:    true,
+				NotifyOnDecrease:    true,
+				WebhookEnabled:      true,
+				WebhookAddr:         legacyWebhookAddr,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m := New(Options{DataDir: t.TempDir()})
+			path := installFixture(t, m, tt.fixture, configFileName)
+			var got Config
+			if err := m.readVersioned(configSchema, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("migrated config = %+v, want %+v", got, tt.want)
+			}
+			if version := storedVersion(t, path); version != configSchema.version() {
+				t.Errorf("stored schemaVersion = %d, want %d", version, configSchema.version())
+			}
+		})
+	}
+}
+
+func TestChangeLogMigrations(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []ChangeLogEntry
+	}{
+		{
+			fixture: "changelog.v0.json",
+			want: []ChangeLogEntry{
+				{
+					Timestamp: mustTime(t, "2025-03-01T09:00:00Z"),
+					Kind:      ChangeKindStats,
+					Total:     12,
+					Delta:     2,
+					Severity:  SeverityCounts{Critical: 1, Severe: 3, Major: 5, Minor: 3},
+				},
+				{
+					Timestamp: mustTime(t, "2025-02-28T17:30:00Z"),
+					Kind:      ChangeKindStats,
+					Total:     10,
+					Delta:     -1,
+					Severity:  SeverityCounts{Critical: 1, Severe: 2, Major: 4, Minor: 3},
+				},
+			},
+		},
+		{
+			fixture: "changelog.v1.json",
+			want: []ChangeLogEntry{
+				{
+					Timestamp: mustTime(t, "2025-06-10T08:15:00Z"),
+					Kind:      ChangeKindStats,
+					Total:     7,
+					Delta:     1,
+					Severity:  SeverityCounts{Severe: 2, Major: 3, Minor: 2},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m := New(Options{DataDir: t.TempDir()})
+			path := installFixture(t, m, tt.fixture, changeLogFileName)
+			var got changeLogFile
+			if err := m.readVersioned(changeLogSchema, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Entries, tt.want) {
+				t.Errorf("migrated entries = %+v, want %+v", got.Entries, tt.want)
+			}
+			if version := storedVersion(t, path); version != changeLogSchema.version() {
+				t.Errorf("stored schemaVersion = %d, want %d", version, changeLogSchema.version())
+			}
+		})
+	}
+}
+
+func TestStateMigrations(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    State
+	}{
+		{
+			fixture: "state.v0.json",
+			want: State{
+				LastStats: Stats{
+					Total:              3,
+					Severity:           SeverityCounts{Critical: 1, Severe: 1, Major: 1},
+					Actionable:         3,
+					ActionableSeverity: SeverityCounts{Critical: 1, Severe: 1, Major: 1},
+					LastUpdated:        mustTime(t, "2025-03-01T09:00:00Z"),
+				},
+			},
+		},
+		{
+			fixture: "state.v1.json",
+			want: State{
+				LastStats: Stats{
+					Total:              2,
+					Severity:           SeverityCounts{Severe: 1, Minor: 1},
+					Actionable:         2,
+					ActionableSeverity: SeverityCounts{Severe: 1, Minor: 1},
+					LastUpdated:        mustTime(t, "2025-06-10T08:15:00Z"),
+				},
+				LastBugs: []Bug{
+					{ID: 201, Title: "保存后数据丢失", Severity: "severe", Status: "激活"},
+					{ID: 202, Title: "提示语有错别字", Severity: "minor", Status: "激活"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			m := New(Options{DataDir: t.TempDir()})
+			path := installFixture(t, m, tt.fixture, stateFileName)
+			var got State
+			if err := m.readVersioned(stateSchema, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("migrated state = %+v, want %+v", got, tt.want)
+			}
+			if version := storedVersion(t, path); version != stateSchema.version() {
+				t.Errorf("stored schemaVersion = %d, want %d", version, stateSchema.version())
+			}
+		})
+	}
+}
+
+func TestDecodeVersionedCurrent(t *testing.T) {
+	data := []byte(`{"schemaVersion": 2, "entries": [{"timestamp": "2025-06-10T08:15:00Z", "kind": "watch", "bugId": 7, "message": "#7 状态 激活→已解决"}]}`)
+	var got changeLogFile
+	version, err := decodeVersioned(data, changeLogSchema, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 {
+		t.Errorf("version = %d, want 2", version)
+	}
+	want := []ChangeLogEntry{{
+		Timestamp: mustTime(t, "2025-06-10T08:15:00Z"),
+		Kind:      ChangeKindWatch,
+		BugID:     7,
+		Message:   "#7 状态 激活→已解决",
+	}}
+	if !reflect.DeepEqual(got.Entries, want) {
+		t.Errorf("entries = %+v, want %+v", got.Entries, want)
+	}
+}
+
+func TestNewerSchemaIsRefused(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir()})
+	path := installFixture(t, m, "state.future.json", stateFileName)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var state State
+	err = m.readVersioned(stateSchema, &state)
+	if !errors.Is(err, errSchemaTooNew) {
+		t.Fatalf("readVersioned error = %v, want errSchemaTooNew", err)
+	}
+	if m.lockedFiles[stateFileName] == nil {
+		t.Errorf("%s not recorded in lockedFiles", stateFileName)
+	}
+
+	err = m.writeVersioned(stateSchema, State{LastStats: Stats{Total: 1}})
+	if !errors.Is(err, errSchemaTooNew) {
+		t.Errorf("writeVersioned error = %v, want errSchemaTooNew", err)
+	}
+	m.removeVersioned(stateSchema)
+	current, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("locked file was removed: %v", err)
+	}
+	if !bytes.Equal(current, original) {
+		t.Errorf("locked file was overwritten:\n%s", current)
+	}
+	if _, err := os.Stat(path + backupSuffix); !os.IsNotExist(err) {
+		t.Errorf("unexpected backup next to the locked file: %v", err)
+	}
+}
+
+func TestReadOnlyLoadWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	m := New(Options{DataDir: dir, Headless: true, ReadOnly: true})
+	path := m.dataPath(configFileName)
+	plain := []byte(`{"url": "http://zentao.example.com/my-work-bug.html", "cookie": "zentaosid=abcdef123456"}`)
+	if err := os.WriteFile(path, plain, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	installFixture(t, m, "state.v0.json", stateFileName)
+	before, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Load()
+	if cfg := m.CurrentConfig(); cfg.Cookie != "zentaosid=abcdef123456" {
+		t.Errorf("read-only cookie = %q, want the plain-text value", cfg.Cookie)
+	}
+	if err := m.SaveConfig(m.CurrentConfig()); !errors.Is(err, errReadOnly) {
+		t.Errorf("SaveConfig on a read-only monitor = %v, want errReadOnly", err)
+	}
+
+	after, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("files after a read-only load = %v, want only %v", after, before)
+	}
+	if current, _ := os.ReadFile(path); !bytes.Equal(current, plain) {
+		t.Errorf("config.json was rewritten:\n%s", current)
+	}
+	if version := storedVersion(t, m.dataPath(stateFileName)); version != 0 {
+		t.Errorf("state.json schemaVersion = %d, want it left at 0", version)
+	}
+}
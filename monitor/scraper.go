@@ -0,0 +1,373 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var numberPattern = regexp.MustCompile(`\d+`)
+
+func (m *Monitor) scrape(ctx context.Context, cfg Config) (Stats, []Bug, int, error) {
+	if len(cfg.Targets) > 0 {
+		return m.scrapeTargets(ctx, cfg)
+	}
+	doc, status, err := m.FetchPage(ctx, cfg)
+	if err != nil {
+		return Stats{}, nil, status, err
+	}
+	stats := ParseStats(doc)
+	stats.LastUpdated = time.Now()
+	return stats, ParseBugs(doc, cfg.URL), status, nil
+}
+
+// FetchPage downloads cfg.URL with cfg.Cookie and parses it as HTML.
+func (m *Monitor) FetchPage(ctx context.Context, cfg Config) (*goquery.Document, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	if cfg.Cookie != "" {
+		req.Header.Set("Cookie", cfg.Cookie)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, resp.StatusCode, &HTTPStatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if isLoginPage(doc) {
+		return nil, resp.StatusCode, ErrLoginRequired
+	}
+	return doc, resp.StatusCode, nil
+}
+
+// ErrLoginRequired means ZenTao answered with its login form, usually
+// because the cookie has expired.
+var ErrLoginRequired = errors.New("login required: the cookie is missing or expired")
+
+func isLoginPage(doc *goquery.Document) bool {
+	return doc.Find("input[type=password]").Length() > 0 && findBugRows(doc).Length() == 0
+}
+
+// HTTPStatusError reports a response with a 4xx or 5xx status.
+type HTTPStatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("bad response: %s", e.Status)
+}
+
+func ParseStats(doc *goquery.Document) Stats {
+	severityIndex := findSeverityIndex(doc)
+	rows := findBugRows(doc)
+	severity := SeverityCounts{}
+
+	rows.Each(func(_ int, row *goquery.Selection) {
+		sevText := extractSeverityText(row, severityIndex)
+		switch normalizeSeverity(sevText) {
+		case "critical":
+			severity.Critical++
+		case "severe":
+			severity.Severe++
+		case "major":
+			severity.Major++
+		case "minor":
+			severity.Minor++
+		}
+	})
+
+	total := parseTotalCount(doc)
+	if total == 0 {
+		total = rows.Length()
+	}
+
+	return Stats{
+		Total:    total,
+		Severity: severity,
+	}
+}
+
+func findBugRows(doc *goquery.Document) *goquery.Selection {
+	candidates := []*goquery.Selection{
+		doc.Find("table#bugList tbody tr"),
+		doc.Find("#bugList tbody tr"),
+		doc.Find("table.datatable tbody tr"),
+		doc.Find("table tbody tr"),
+	}
+	for _, rows := range candidates {
+		filtered := rows.FilterFunction(func(_ int, sel *goquery.Selection) bool {
+			return sel.Find("td").Length() > 0
+		})
+		if filtered.Length() > 0 {
+			return filtered
+		}
+	}
+	return doc.Find("table#bugList tbody tr")
+}
+
+func findSeverityIndex(doc *goquery.Document) int {
+	headers := doc.Find("table#bugList thead th")
+	if headers.Length() == 0 {
+		headers = doc.Find("table thead th")
+	}
+	index := -1
+	headers.Each(func(i int, header *goquery.Selection) {
+		text := strings.TrimSpace(header.Text())
+		lower := strings.ToLower(text)
+		if strings.Contains(text, "严重") || strings.Contains(text, "致命") || strings.Contains(lower, "severity") {
+			index = i
+		}
+	})
+	return index
+}
+
+func extractSeverityText(row *goquery.Selection, index int) string {
+	thirdCellSpan := strings.TrimSpace(row.Find("td:nth-child(3) span").First().Text())
+	if thirdCellSpan != "" {
+		return thirdCellSpan
+	}
+	selectors := []string{
+		"td.c-severity",
+		"td.severity",
+		"td[data-col='severity']",
+		"td[data-type='severity']",
+	}
+	for _, selector := range selectors {
+		text := strings.TrimSpace(row.Find(selector).First().Text())
+		if text != "" {
+			return text
+		}
+	}
+	if index >= 0 {
+		cells := row.Find("td")
+		if index < cells.Length() {
+			return strings.TrimSpace(cells.Eq(index).Text())
+		}
+	}
+	return ""
+}
+
+func normalizeSeverity(text string) string {
+	if text == "" {
+		return ""
+	}
+	value := strings.TrimSpace(text)
+	lower := strings.ToLower(value)
+	if strings.Contains(value, "致命") || strings.Contains(lower, "critical") || strings.Contains(lower, "blocker") {
+		return "critical"
+	}
+	if strings.Contains(value, "严重") || strings.Contains(lower, "high") {
+		return "severe"
+	}
+	if strings.Contains(value, "主要") || strings.Contains(lower, "major") {
+		return "major"
+	}
+	if strings.Contains(value, "次要") || strings.Contains(value, "轻微") || strings.Contains(lower, "minor") {
+		return "minor"
+	}
+	if match := numberPattern.FindString(lower); match != "" {
+		if num, err := strconv.Atoi(match); err == nil {
+			switch num {
+			case 1:
+				return "critical"
+			case 2:
+				return "severe"
+			case 3:
+				return "major"
+			case 4:
+				return "minor"
+			}
+		}
+	}
+	return ""
+}
+
+func parseTotalCount(doc *goquery.Document) int {
+	selectors := []string{
+		"#bugCount",
+		".pager .page-summary",
+		".pager .total",
+		".page-summary",
+		".table-footer",
+		".table-actions",
+	}
+	for _, selector := range selectors {
+		text := strings.TrimSpace(doc.Find(selector).First().Text())
+		if count := parseNumber(text); count > 0 {
+			return count
+		}
+	}
+	return 0
+}
+
+func parseNumber(text string) int {
+	match := numberPattern.FindString(text)
+	if match == "" {
+		return 0
+	}
+	value, err := strconv.Atoi(match)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// ParseBugs extracts one Bug per row of the bug table. pageURL is used to
+// resolve the bug links.
+func ParseBugs(doc *goquery.Document, pageURL string) []Bug {
+	columns := findColumnIndexes(doc)
+	severityIndex := findSeverityIndex(doc)
+	base, _ := url.Parse(pageURL)
+	bugs := make([]Bug, 0)
+	findBugRows(doc).Each(func(_ int, row *goquery.Selection) {
+		bug := Bug{
+			Severity:   normalizeSeverity(extractSeverityText(row, severityIndex)),
+			Priority:   cellText(row, columns, "pri", "td.c-pri"),
+			Status:     cellText(row, columns, "status", "td.c-status"),
+			Module:     cellText(row, columns, "module", "td.c-module"),
+			OpenedBy:   cellText(row, columns, "openedBy", "td.c-openedBy"),
+			AssignedTo: cellText(row, columns, "assignedTo", "td.c-assignedTo"),
+		}
+		link := row.Find("a[href*='bug-view'], a[href*='f=view']").First()
+		bug.Title = strings.TrimSpace(link.AttrOr("title", ""))
+		if bug.Title == "" {
+			bug.Title = cellText(row, columns, "title", "td.c-title")
+		}
+		if bug.Title == "" {
+			bug.Title = strings.TrimSpace(link.Text())
+		}
+		bug.ID = parseNumber(cellText(row, columns, "id", "td.c-id"))
+		if href, ok := link.Attr("href"); ok {
+			if bug.ID == 0 {
+				bug.ID = parseNumber(bugIDPattern.FindString(href))
+			}
+			if base != nil {
+				if ref, err := url.Parse(href); err == nil {
+					bug.URL = base.ResolveReference(ref).String()
+				}
+			}
+		}
+		if bug.ID == 0 {
+			return
+		}
+		if bug.URL == "" {
+			bug.URL = bugViewURL(pageURL, bug.ID)
+		}
+		bugs = append(bugs, bug)
+	})
+	return bugs
+}
+
+var bugIDPattern = regexp.MustCompile(`(?:bug-view-|bugID=)\d+`)
+
+// findColumnIndexes maps the bug table's header cells to field names.
+func findColumnIndexes(doc *goquery.Document) map[string]int {
+	headers := doc.Find("table#bugList thead th")
+	if headers.Length() == 0 {
+		headers = doc.Find("table thead th")
+	}
+	columns := map[string]int{}
+	headers.Each(func(i int, header *goquery.Selection) {
+		text := strings.TrimSpace(header.Text())
+		lower := strings.ToLower(text)
+		var key string
+		switch {
+		case lower == "id" || strings.HasPrefix(lower, "id "):
+			key = "id"
+		case strings.Contains(text, "标题") || strings.Contains(lower, "title"):
+			key = "title"
+		case strings.Contains(text, "优先级") || lower == "p" || strings.Contains(lower, "pri"):
+			key = "pri"
+		case strings.Contains(text, "状态") || strings.Contains(lower, "status"):
+			key = "status"
+		case strings.Contains(text, "模块") || strings.Contains(lower, "module"):
+			key = "module"
+		case strings.Contains(text, "创建者") || strings.Contains(text, "由谁创建") || strings.Contains(lower, "opened by"):
+			key = "openedBy"
+		case strings.Contains(text, "指派") || strings.Contains(lower, "assigned"):
+			key = "assignedTo"
+		default:
+			return
+		}
+		if _, ok := columns[key]; !ok {
+			columns[key] = i
+		}
+	})
+	return columns
+}
+
+func cellText(row *goquery.Selection, columns map[string]int, key, selector string) string {
+	if text := strings.TrimSpace(row.Find(selector).First().Text()); text != "" {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	index, ok := columns[key]
+	if !ok {
+		return ""
+	}
+	cells := row.Find("td")
+	if index >= cells.Length() {
+		return ""
+	}
+	return strings.Join(strings.Fields(cells.Eq(index).Text()), " ")
+}
+
+// BugURL returns the bug-view page for id on the configured ZenTao, or ""
+// when the configured URL is unusable.
+func (m *Monitor) BugURL(id int) string {
+	return bugViewURL(m.currentConfig().URL, id)
+}
+
+// bugViewURL builds the bug-view page URL for id on the same ZenTao as
+// pageURL, following its request type: index.php?m=...&f=... for GET
+// installs, /bug-view-<id>.html for PATH_INFO ones.
+func bugViewURL(pageURL string, id int) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	parsed.Fragment = ""
+	if parsed.Query().Get("m") != "" {
+		query := url.Values{}
+		query.Set("m", "bug")
+		query.Set("f", "view")
+		query.Set("bugID", strconv.Itoa(id))
+		parsed.RawQuery = query.Encode()
+		return parsed.String()
+	}
+	dir := parsed.Path
+	if index := strings.LastIndex(dir, "/"); index >= 0 {
+		dir = dir[:index+1]
+	} else {
+		dir = "/"
+	}
+	parsed.Path = dir + fmt.Sprintf("bug-view-%d.html", id)
+	parsed.RawQuery = ""
+	return parsed.String()
+}
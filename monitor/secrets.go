@@ -0,0 +1,381 @@
+package monitor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	secretsFileName   = "secrets.json"
+	secretKeyFileName = "secret.key"
+	keyringService    = "ZenTaoBugMonitor"
+
+	secretBackendFile    = "file"
+	secretBackendKeyring = "keyring"
+
+	secretCookie     = "cookie"
+	secretAPIToken   = "apiToken"
+	secretSubscriber = "subscriberToken"
+	secretRelayToken = "relayToken"
+	secretWebhook    = "webhookSecret"
+
+	// secretMask replaces secret values in everything handed to the frontend
+	// and in log lines. SaveConfig treats it as "unchanged".
+	secretMask = "••••••••"
+)
+
+var errSecretNotFound = errors.New("secret not found")
+
+type secretStore interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+var secretsSchema = fileSchema{
+	name: secretsFileName,
+	migrations: []migration{
+		stampVersion,
+	},
+}
+
+// fileSecretStore keeps secrets AES-GCM encrypted in secrets.json with a
+// random key generated on first use and stored next to it with owner-only
+// permissions.
+type fileSecretStore struct {
+	mu       sync.Mutex
+	path     string
+	keyPath  string
+	readOnly bool
+}
+
+type secretsFile struct {
+	Secrets map[string]string `json:"secrets"`
+}
+
+func newFileSecretStore(dataDir string) *fileSecretStore {
+	return &fileSecretStore{
+		path:    filepath.Join(dataDir, secretsFileName),
+		keyPath: filepath.Join(dataDir, secretKeyFileName),
+	}
+}
+
+func (s *fileSecretStore) Name() string {
+	return secretBackendFile
+}
+
+func (s *fileSecretStore) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := secrets[key]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	aead, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("decode secret %q: %w", key, err)
+	}
+	if len(raw) < aead.NonceSize() {
+		return "", fmt.Errorf("secret %q is truncated", key)
+	}
+	plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %q: %w", key, err)
+	}
+	return string(plain), nil
+}
+
+func (s *fileSecretStore) Set(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	aead, err := s.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(key))
+	secrets[key] = base64.StdEncoding.EncodeToString(sealed)
+	return s.save(secrets)
+}
+
+func (s *fileSecretStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *fileSecretStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file secretsFile
+	if _, err := decodeVersioned(data, secretsSchema, &file); err != nil {
+		return nil, err
+	}
+	if file.Secrets == nil {
+		file.Secrets = map[string]string{}
+	}
+	return file.Secrets, nil
+}
+
+func (s *fileSecretStore) save(secrets map[string]string) error {
+	doc, err := encodeVersioned(secretsSchema, secretsFile{Secrets: secrets})
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data, 0o600)
+}
+
+func (s *fileSecretStore) cipher() (cipher.AEAD, error) {
+	key, err := os.ReadFile(s.keyPath)
+	if errors.Is(err, os.ErrNotExist) && s.readOnly {
+		return nil, fmt.Errorf("%s is missing", secretKeyFileName)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(s.keyPath, key, 0o600); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%s has an invalid length", secretKeyFileName)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyringSecretStore uses the desktop keyring: Secret Service on Linux,
+// Keychain on macOS and Credential Manager on Windows.
+type keyringSecretStore struct{}
+
+func (keyringSecretStore) Name() string {
+	return secretBackendKeyring
+}
+
+func (keyringSecretStore) Get(key string) (string, error) {
+	value, err := keyring.Get(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", errSecretNotFound
+	}
+	return value, err
+}
+
+func (keyringSecretStore) Set(key, value string) error {
+	return keyring.Set(keyringService, key, value)
+}
+
+func (keyringSecretStore) Delete(key string) error {
+	err := keyring.Delete(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// openSecretStore returns the requested backend, falling back to the
+// encrypted file when the keyring cannot be reached (no Secret Service on a
+// headless Linux box, for example).
+func (m *Monitor) openSecretStore(backend string) secretStore {
+	fileStore := newFileSecretStore(m.ensureDataDir())
+	fileStore.readOnly = m.readOnly
+	if backend != secretBackendKeyring {
+		return fileStore
+	}
+	store := keyringSecretStore{}
+	if _, err := store.Get(secretCookie); err != nil && !errors.Is(err, errSecretNotFound) {
+		m.addLog("warn", fmt.Sprintf("Keyring unavailable (%v), using encrypted file", err), 0)
+		return fileStore
+	}
+	return store
+}
+
+// configSecrets lists the secret-bearing fields of cfg by store key.
+func configSecrets(cfg *Config) map[string]*string {
+	return map[string]*string{
+		secretCookie:     &cfg.Cookie,
+		secretAPIToken:   &cfg.APIToken,
+		secretSubscriber: &cfg.SubscriberToken,
+		secretRelayToken: &cfg.RelayToken,
+		secretWebhook:    &cfg.WebhookSecret,
+	}
+}
+
+// loadSecrets fills cfg's secret fields from the store. Values still present
+// in the file itself predate the store; they are moved into it and reported
+// through the returned flag so the caller can rewrite the file without them.
+// The flag stays false if any of them could not be stored, leaving the file
+// and its backup as they are until a later start moves them all. A
+// read-only monitor uses such values where they are.
+func (m *Monitor) loadSecrets(store secretStore, cfg *Config) (migrated bool) {
+	failed := false
+	for key, field := range configSecrets(cfg) {
+		if *field != "" && m.readOnly {
+			continue
+		}
+		if *field != "" {
+			if err := store.Set(key, *field); err != nil {
+				m.addLog("error", fmt.Sprintf("Moving %s into the %s secret store failed: %v", key, store.Name(), err), 0)
+				failed = true
+				continue
+			}
+			m.addLog("info", fmt.Sprintf("Moved plain-text %s into the %s secret store", key, store.Name()), 0)
+			migrated = true
+			continue
+		}
+		value, err := store.Get(key)
+		if err != nil && !errors.Is(err, errSecretNotFound) {
+			m.addLog("error", fmt.Sprintf("Reading %s from the %s secret store failed: %v", key, store.Name(), err), 0)
+		}
+		*field = value
+	}
+	return migrated && !failed
+}
+
+func (m *Monitor) saveSecrets(store secretStore, cfg Config) error {
+	for key, field := range configSecrets(&cfg) {
+		var err error
+		if *field == "" {
+			err = store.Delete(key)
+		} else {
+			err = store.Set(key, *field)
+		}
+		if err != nil {
+			return fmt.Errorf("saving %s to the %s secret store: %w", key, store.Name(), err)
+		}
+	}
+	return nil
+}
+
+// switchSecretStore copies every secret into the newly selected backend and
+// removes it from the old one.
+func (m *Monitor) switchSecretStore(from, to secretStore, cfg Config) error {
+	if err := m.saveSecrets(to, cfg); err != nil {
+		return err
+	}
+	for key := range configSecrets(&cfg) {
+		_ = from.Delete(key)
+	}
+	m.addLog("info", fmt.Sprintf("Secrets moved from %s to %s store", from.Name(), to.Name()), 0)
+	return nil
+}
+
+// stripSecrets returns cfg with every secret field cleared, for writing to
+// config.json.
+func stripSecrets(cfg Config) Config {
+	cfg.NotifyLevels = copyLevels(cfg.NotifyLevels)
+	for _, field := range configSecrets(&cfg) {
+		*field = ""
+	}
+	return cfg
+}
+
+// maskConfig returns cfg with every non-empty secret replaced by secretMask.
+func maskConfig(cfg Config) Config {
+	cfg.NotifyLevels = copyLevels(cfg.NotifyLevels)
+	for _, field := range configSecrets(&cfg) {
+		if *field != "" {
+			*field = secretMask
+		}
+	}
+	return cfg
+}
+
+// unmaskConfig restores secrets the frontend sent back still masked.
+func unmaskConfig(cfg Config, current Config) Config {
+	currentSecrets := configSecrets(&current)
+	for key, field := range configSecrets(&cfg) {
+		if *field == secretMask {
+			*field = *currentSecrets[key]
+		}
+	}
+	return cfg
+}
+
+// MaskSecrets hides secret values in message. Cookies are also masked value
+// by value, so a single session id quoted in an error is caught too.
+func MaskSecrets(message string, cfg Config) string {
+	values := make([]string, 0)
+	for key, field := range configSecrets(&cfg) {
+		if *field == "" {
+			continue
+		}
+		values = append(values, *field)
+		if key == secretCookie {
+			for _, pair := range strings.Split(*field, ";") {
+				if _, value, ok := strings.Cut(pair, "="); ok && len(strings.TrimSpace(value)) >= 6 {
+					values = append(values, strings.TrimSpace(value))
+				}
+			}
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	for _, value := range values {
+		message = strings.ReplaceAll(message, value, secretMask)
+	}
+	return message
+}
+
+func copyLevels(levels map[string]bool) map[string]bool {
+	if levels == nil {
+		return nil
+	}
+	copied := make(map[string]bool, len(levels))
+	for key, value := range levels {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,59 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+)
+
+// failingSecretStore keeps secrets in memory and refuses to store the keys
+// in fail.
+type failingSecretStore struct {
+	values map[string]string
+	fail   map[string]bool
+}
+
+func (s *failingSecretStore) Name() string { return "test" }
+
+func (s *failingSecretStore) Get(key string) (string, error) {
+	value, ok := s.values[key]
+	if !ok {
+		return "", errSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *failingSecretStore) Set(key, value string) error {
+	if s.fail[key] {
+		return errors.New("store locked")
+	}
+	s.values[key] = value
+	return nil
+}
+
+func (s *failingSecretStore) Delete(key string) error {
+	delete(s.values, key)
+	return nil
+}
+
+func TestLoadSecretsMigratesOnlyWhenEveryValueIsStored(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	plain := Config{Cookie: "zentaosid=abcdef123456", APIToken: "token-1"}
+
+	store := &failingSecretStore{values: map[string]string{}, fail: map[string]bool{secretCookie: true}}
+	cfg := plain
+	if m.loadSecrets(store, &cfg) {
+		t.Error("loadSecrets reported a migration although the cookie could not be stored")
+	}
+	if cfg.Cookie != plain.Cookie || cfg.APIToken != plain.APIToken {
+		t.Errorf("secrets after a failed move = %q %q, want the plain-text values", cfg.Cookie, cfg.APIToken)
+	}
+
+	store.fail = nil
+	cfg = plain
+	if !m.loadSecrets(store, &cfg) {
+		t.Error("loadSecrets did not report a migration once every value was stored")
+	}
+	if store.values[secretCookie] != plain.Cookie || store.values[secretAPIToken] != plain.APIToken {
+		t.Errorf("stored secrets = %v", store.values)
+	}
+}
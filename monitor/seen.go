@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+)
+
+const seenFileName = "seen.json"
+
+var seenSchema = fileSchema{
+	name: seenFileName,
+	migrations: []migration{
+		stampVersion,
+	},
+}
+
+// seenBug records when a bug first appeared in the list and when it was
+// looked at. SeenAt stays zero while the bug is unread.
+type seenBug struct {
+	ID        int       `json:"id"`
+	FirstSeen time.Time `json:"firstSeen"`
+	SeenAt    time.Time `json:"seenAt"`
+}
+
+type seenFile struct {
+	Bugs []seenBug `json:"bugs"`
+}
+
+func (m *Monitor) loadSeen() error {
+	var file seenFile
+	if err := m.readVersioned(seenSchema, &file); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.seen = make(map[int]seenBug, len(file.Bugs))
+	for _, bug := range file.Bugs {
+		m.seen[bug.ID] = bug
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Monitor) saveSeen() error {
+	m.mu.Lock()
+	file := seenFile{Bugs: make([]seenBug, 0, len(m.seen))}
+	for _, bug := range m.seen {
+		file.Bugs = append(file.Bugs, bug)
+	}
+	m.mu.Unlock()
+	sort.Slice(file.Bugs, func(i, j int) bool { return file.Bugs[i].ID < file.Bugs[j].ID })
+	return m.writeVersioned(seenSchema, file)
+}
+
+// trackSeen records the first sighting of each bug in the list and forgets
+// the bugs that left it, so one that comes back is new again. Without any
+// earlier record, as on the first sync, the whole list counts as already
+// seen. The caller holds m.mu.
+func (m *Monitor) trackSeen(bugs []Bug, now time.Time) {
+	baseline := m.seen == nil
+	current := make(map[int]seenBug, len(bugs))
+	for _, bug := range bugs {
+		record, ok := m.seen[bug.ID]
+		if !ok {
+			record = seenBug{ID: bug.ID, FirstSeen: now}
+			if baseline {
+				record.SeenAt = now
+			}
+		}
+		current[bug.ID] = record
+	}
+	m.seen = current
+}
+
+// flagUnread sets FirstSeen and Unread on each bug and counts the unread
+// ones. Muted bugs are never unread. The caller holds m.mu.
+func (m *Monitor) flagUnread(stats Stats, bugs []Bug) (Stats, []Bug) {
+	flagged := make([]Bug, len(bugs))
+	stats.Unread = 0
+	for i, bug := range bugs {
+		record := m.seen[bug.ID]
+		bug.FirstSeen = record.FirstSeen
+		bug.Unread = record.ID != 0 && record.SeenAt.IsZero() && !bug.Muted
+		if bug.Unread {
+			stats.Unread++
+		}
+		flagged[i] = bug
+	}
+	return stats, flagged
+}
+
+// GetUnread returns the bugs that appeared since they were last looked at,
+// newest first.
+func (m *Monitor) GetUnread() []Bug {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unread := []Bug{}
+	for _, bug := range m.bugs {
+		if bug.Unread {
+			unread = append(unread, bug)
+		}
+	}
+	sort.SliceStable(unread, func(i, j int) bool {
+		if !unread[i].FirstSeen.Equal(unread[j].FirstSeen) {
+			return unread[i].FirstSeen.After(unread[j].FirstSeen)
+		}
+		return unread[i].ID > unread[j].ID
+	})
+	return unread
+}
+
+// MarkSeen marks every bug in the list as seen, as when the window is
+// opened.
+func (m *Monitor) MarkSeen() error {
+	return m.markSeen(func(int) bool { return true })
+}
+
+// MarkBugSeen marks one bug as seen, as when it is opened.
+func (m *Monitor) MarkBugSeen(id int) error {
+	return m.markSeen(func(bugID int) bool { return bugID == id })
+}
+
+func (m *Monitor) markSeen(match func(id int) bool) error {
+	now := time.Now()
+	m.mu.Lock()
+	changed := false
+	for id, record := range m.seen {
+		if record.SeenAt.IsZero() && match(id) {
+			record.SeenAt = now
+			m.seen[id] = record
+			changed = true
+		}
+	}
+	if !changed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stats, m.bugs = m.flagUnread(m.stats, m.bugs)
+	stats, bugs := m.stats, m.bugs
+	m.mu.Unlock()
+	if err := m.saveSeen(); err != nil {
+		return err
+	}
+	_ = m.saveState(stats, bugs)
+	m.emitStats()
+	return nil
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const serverShutdownTimeout = 5 * time.Second
+
+// localServer is one of the HTTP listeners the monitor runs: the local API
+// and the webhook receiver.
+type localServer struct {
+	name   string
+	addr   string
+	server *http.Server
+	cancel context.CancelFunc
+}
+
+// listenHTTP binds addr and serves handler in the background. Failures are
+// logged under name as well as returned.
+func (m *Monitor) listenHTTP(name, addr string, handler http.Handler) (*localServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		m.addLog("error", fmt.Sprintf("%s failed to listen on %s: %v", name, addr, err), 0)
+		return nil, err
+	}
+	// Cancelling the base context ends long-lived requests such as /events
+	// streams, which would otherwise hold Shutdown until its timeout.
+	baseCtx, cancel := context.WithCancel(context.Background())
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+	}
+	m.addLog("info", fmt.Sprintf("%s listening on http://%s", name, addr), 0)
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.addLog("error", fmt.Sprintf("%s stopped: %v", name, err), 0)
+		}
+	}()
+	return &localServer{name: name, addr: addr, server: server, cancel: cancel}, nil
+}
+
+func (m *Monitor) closeHTTP(s *localServer) {
+	if s == nil {
+		return
+	}
+	s.cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+	}
+	m.addLog("info", s.name+" stopped", 0)
+}
+
+// ensureToken generates the secret field selects when it is still empty
+// and saves it, so a listener never starts without one.
+func (m *Monitor) ensureToken(label string, field func(*Config) *string) error {
+	m.mu.Lock()
+	if *field(&m.config) != "" {
+		m.mu.Unlock()
+		return nil
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		m.mu.Unlock()
+		m.addLog("error", fmt.Sprintf("%s generation failed: %v", label, err), 0)
+		return err
+	}
+	*field(&m.config) = hex.EncodeToString(buf)
+	cfg := m.config
+	m.mu.Unlock()
+	if err := m.saveConfig(cfg); err != nil {
+		m.addLog("error", fmt.Sprintf("Saving %s failed: %v", label, err), 0)
+	}
+	m.addLog("info", fmt.Sprintf("Generated a new %s", label), 0)
+	return nil
+}
@@ -0,0 +1,70 @@
+package monitor
+
+import "sync"
+
+// Events published through EventSink. The payload of each is the value the
+// matching getter returns (EventStats carries GetStats(), and so on), except
+// EventPlaySound whose payload is whether the sound is forced.
+const (
+	EventConfig     = "config"
+	EventStats      = "stats"
+	EventChangeLog  = "changelog"
+	EventLogs       = "logs"
+	EventMonitoring = "monitoring"
+	EventHealth     = "health"
+	EventPlaySound  = "play-sound"
+	EventWatchlist  = "watchlist"
+)
+
+// EventSink is how a frontend follows the monitor. Emit is called
+// synchronously from whichever goroutine changed the data, so
+// implementations must be safe for concurrent use and return quickly.
+type EventSink interface {
+	Emit(name string, payload any)
+}
+
+// Event is one emitted event as captured by RecordingSink.
+type Event struct {
+	Name    string
+	Payload any
+}
+
+// RecordingSink keeps every event in memory, for tests and for frontends
+// that poll rather than subscribe.
+type RecordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *RecordingSink) Emit(name string, payload any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, Event{Name: name, Payload: payload})
+}
+
+// Events returns a copy of everything recorded so far.
+func (s *RecordingSink) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Named returns the recorded events called name, oldest first.
+func (s *RecordingSink) Named(name string) []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	matched := make([]Event, 0)
+	for _, event := range s.events {
+		if event.Name == name {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// Reset forgets everything recorded so far.
+func (s *RecordingSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+}
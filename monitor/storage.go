@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	configFileName    = "config.json"
+	stateFileName     = "state.json"
+	changeLogFileName = "changelog.json"
+
+	backupSuffix = ".bak"
+)
+
+// errReadOnly is returned by saves on a monitor created with
+// Options.ReadOnly.
+var errReadOnly = errors.New("data directory opened read-only")
+
+func (m *Monitor) ensureDataDir() string {
+	if m.dataDir != "" {
+		return m.dataDir
+	}
+	baseDir, err := os.UserConfigDir()
+	if err != nil {
+		baseDir = "."
+	}
+	path := filepath.Join(baseDir, "ZenTaoBugMonitor")
+	_ = os.MkdirAll(path, 0o755)
+	m.dataDir = path
+	return path
+}
+
+// dataPath returns where the named data file lives. Only config.json can be
+// relocated, via the --config flag.
+func (m *Monitor) dataPath(name string) string {
+	if name == configFileName && m.configPath != "" {
+		return m.configPath
+	}
+	return filepath.Join(m.ensureDataDir(), name)
+}
+
+// ConfigPath returns where config.json is read from and written to.
+func (m *Monitor) ConfigPath() string {
+	return m.dataPath(configFileName)
+}
+
+// LoadFile decodes name, a JSON file a frontend keeps in the data directory,
+// into v. A corrupt file is recovered from its backup like the monitor's own
+// files; a missing one is reported as-is.
+func (m *Monitor) LoadFile(name string, v any) error {
+	return m.readDataFile(filepath.Join(m.ensureDataDir(), name), func(data []byte) error {
+		return json.Unmarshal(data, v)
+	})
+}
+
+// SaveFile replaces name in the data directory with v, keeping the previous
+// contents as the backup LoadFile falls back to.
+func (m *Monitor) SaveFile(name string, v any) error {
+	if m.readOnly {
+		return fmt.Errorf("not saving %s: %w", name, errReadOnly)
+	}
+	return writeJSON(filepath.Join(m.ensureDataDir(), name), v)
+}
+
+// readDataFile reads path and hands the bytes to decode. When the file
+// exists but cannot be decoded it is moved aside and the last good backup is
+// restored in its place; a missing file is reported as-is so deliberate
+// deletes stay deleted. Decode errors wrapped in errKeepFile are returned
+// without touching the file.
+func (m *Monitor) readDataFile(path string, decode func([]byte) error) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	decodeErr := decode(data)
+	if decodeErr == nil {
+		return nil
+	}
+	var keep errKeepFile
+	if errors.As(decodeErr, &keep) {
+		return decodeErr
+	}
+	if m.readOnly {
+		// Use the backup without moving anything; the next instance that
+		// can write restores it.
+		if backup, err := os.ReadFile(path + backupSuffix); err == nil && decode(backup) == nil {
+			return nil
+		}
+		return decodeErr
+	}
+
+	name := filepath.Base(path)
+	corruptPath := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, corruptPath); err != nil {
+		corruptPath = path
+	}
+	m.addLog("error", fmt.Sprintf("%s is corrupt (%v), kept as %s", name, decodeErr, filepath.Base(corruptPath)), 0)
+
+	backup, err := os.ReadFile(path + backupSuffix)
+	if err != nil {
+		m.addLog("error", fmt.Sprintf("No backup available for %s", name), 0)
+		return decodeErr
+	}
+	if err := decode(backup); err != nil {
+		m.addLog("error", fmt.Sprintf("Backup of %s is unusable: %v", name, err), 0)
+		return decodeErr
+	}
+	if err := writeFileAtomic(path, backup, 0o644); err != nil {
+		m.addLog("error", fmt.Sprintf("Restoring %s from backup failed: %v", name, err), 0)
+	} else {
+		m.addLog("warn", fmt.Sprintf("Recovered %s from backup", name), 0)
+	}
+	return nil
+}
+
+// writeJSON replaces path atomically. The previous contents, when they are
+// valid JSON, become the backup that readDataFile, and so readVersioned and
+// LoadFile, falls back to.
+func writeJSON(path string, payload any) error {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	if current, err := os.ReadFile(path); err == nil && json.Valid(current) {
+		_ = writeFileAtomic(path+backupSuffix, current, 0o644)
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+func removeJSON(path string) {
+	_ = os.Remove(path)
+	_ = os.Remove(path + backupSuffix)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory, fsyncs
+// it and renames it over path, so readers see either the old or the new
+// contents but never a partial write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	cleanup := func(err error) error {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return cleanup(err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return cleanup(err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir flushes the directory entry after a rename. Not every platform
+// allows fsync on a directory, so failures are ignored.
+func syncDir(dir string) {
+	handle, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = handle.Sync()
+	_ = handle.Close()
+}
@@ -0,0 +1,182 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	streamBacklog     = 256
+	streamClientQueue = 64
+	streamHeartbeat   = 30 * time.Second
+)
+
+// streamEvent is one event as sent on /events, already encoded so every
+// subscriber shares the same bytes.
+type streamEvent struct {
+	ID   uint64
+	Name string
+	Data []byte
+}
+
+// eventHub numbers every emitted event and fans it out to the /events
+// subscribers. It keeps the last streamBacklog events so a client that
+// reconnects with Last-Event-ID gets what it missed.
+type eventHub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	backlog     []streamEvent
+	subscribers map[chan streamEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{nextID: 1, subscribers: map[chan streamEvent]struct{}{}}
+}
+
+func (h *eventHub) Emit(name string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	event := streamEvent{ID: h.nextID, Name: name, Data: data}
+	h.nextID++
+	h.backlog = append(h.backlog, event)
+	if len(h.backlog) > streamBacklog {
+		h.backlog = h.backlog[len(h.backlog)-streamBacklog:]
+	}
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// A subscriber this far behind reconnects and resumes from its
+			// last ID instead of stalling the emitter.
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns the backlog after lastID.
+// complete is false when lastID is zero or has already left the backlog;
+// the caller then sends a snapshot of the current state first.
+func (h *eventHub) subscribe(lastID uint64) (ch chan streamEvent, missed []streamEvent, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ch = make(chan streamEvent, streamClientQueue)
+	h.subscribers[ch] = struct{}{}
+	if lastID == 0 || lastID >= h.nextID {
+		return ch, nil, false
+	}
+	complete = len(h.backlog) > 0 && h.backlog[0].ID <= lastID+1
+	for _, event := range h.backlog {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+	return ch, missed, complete
+}
+
+func (h *eventHub) unsubscribe(ch chan streamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[ch]; ok {
+		delete(h.subscribers, ch)
+		close(ch)
+	}
+}
+
+// snapshotEvents returns the current value of every state event, in the
+// order emitAll sends them.
+func (m *Monitor) snapshotEvents() []Event {
+	return []Event{
+		{Name: EventConfig, Payload: m.GetConfig()},
+		{Name: EventStats, Payload: m.GetStats()},
+		{Name: EventChangeLog, Payload: m.GetChangeLog()},
+		{Name: EventLogs, Payload: m.GetLogs()},
+		{Name: EventMonitoring, Payload: m.isMonitoringEnabled()},
+		{Name: EventHealth, Payload: m.GetHealth()},
+		{Name: EventWatchlist, Payload: m.GetWatchedBugs()},
+	}
+}
+
+// handleAPIEvents serves the event stream as Server-Sent Events. Each
+// message carries the event name and its JSON payload, matching what the
+// Wails frontend receives; subscriber connections only get
+// subscriberEvents. Clients resume with the Last-Event-ID header
+// (EventSource sends it on reconnect) or ?lastEventId=.
+func (m *Monitor) handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	lastParam := r.Header.Get("Last-Event-ID")
+	if lastParam == "" {
+		lastParam = r.URL.Query().Get("lastEventId")
+	}
+	lastID, _ := strconv.ParseUint(lastParam, 10, 64)
+	allowed := func(name string) bool {
+		return !isSubscriber(r) || subscriberEvents[name]
+	}
+
+	ch, missed, complete := m.events.subscribe(lastID)
+	defer m.events.unsubscribe(ch)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	if !complete {
+		// Snapshot events carry no id, so the client keeps resuming from
+		// the last numbered event it saw.
+		for _, event := range m.snapshotEvents() {
+			if !allowed(event.Name) {
+				continue
+			}
+			data, err := json.Marshal(event.Payload)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, data)
+		}
+	}
+	for _, event := range missed {
+		if allowed(event.Name) {
+			writeStreamEvent(w, event)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if allowed(event.Name) {
+				writeStreamEvent(w, event)
+				flusher.Flush()
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeStreamEvent(w http.ResponseWriter, event streamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Name, event.Data)
+}
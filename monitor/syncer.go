@@ -0,0 +1,80 @@
+package monitor
+
+import "time"
+
+// syncRun is one scrape shared by every request that arrived before it
+// started. done is closed once err is set.
+type syncRun struct {
+	done chan struct{}
+	err  error
+}
+
+func newSyncRun() *syncRun {
+	return &syncRun{done: make(chan struct{})}
+}
+
+func (r *syncRun) wait() error {
+	<-r.done
+	return r.err
+}
+
+// TriggerSync requests a sync without waiting for it.
+func (m *Monitor) TriggerSync() {
+	m.requestSync()
+}
+
+// requestSync returns the run that will cover a request made now: a fresh
+// run when idle, otherwise the single follow-up queued behind the active one.
+func (m *Monitor) requestSync() *syncRun {
+	m.mu.Lock()
+	if m.activeSync == nil {
+		run := newSyncRun()
+		m.activeSync = run
+		m.mu.Unlock()
+		go m.syncLoop(run)
+		return run
+	}
+	if m.pendingSync != nil {
+		run := m.pendingSync
+		m.mu.Unlock()
+		return run
+	}
+	run := newSyncRun()
+	m.pendingSync = run
+	m.mu.Unlock()
+	m.addLog("info", "Sync queued: will run again after the current scrape", 0)
+	return run
+}
+
+func (m *Monitor) syncLoop(run *syncRun) {
+	for run != nil {
+		run.err = m.runSync()
+		close(run.done)
+		m.startWatchPoll()
+
+		m.mu.Lock()
+		run = m.pendingSync
+		m.pendingSync = nil
+		m.activeSync = run
+		m.mu.Unlock()
+	}
+}
+
+// waitSyncIdle blocks until no sync is running or timeout elapses.
+func (m *Monitor) waitSyncIdle(timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		m.mu.Lock()
+		run := m.activeSync
+		m.mu.Unlock()
+		if run == nil {
+			return true
+		}
+		select {
+		case <-run.done:
+		case <-deadline.C:
+			return false
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// primaryTargetName labels the bugs found on Config.URL once extra
+	// targets are configured.
+	primaryTargetName = "default"
+	maxTargets        = 10
+)
+
+// Target is another ZenTao bug-list page polled alongside Config.URL, with
+// the same cookie, for example a product's or a teammate's bug list. A
+// relay polls them all and publishes the merged result.
+type Target struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// TargetStats is one target's share of a multi-target sync.
+type TargetStats struct {
+	Name     string         `json:"name"`
+	URL      string         `json:"url"`
+	Total    int            `json:"total"`
+	Severity SeverityCounts `json:"severity"`
+}
+
+func sanitizeTargets(targets []Target, primary string) []Target {
+	cleaned := make([]Target, 0, len(targets))
+	seen := map[string]bool{primary: true}
+	names := map[string]bool{primaryTargetName: true}
+	for _, target := range targets {
+		target.URL = strings.TrimSpace(target.URL)
+		target.Name = strings.TrimSpace(target.Name)
+		if target.URL == "" || seen[target.URL] {
+			continue
+		}
+		if target.Name == "" || names[target.Name] {
+			target.Name = target.URL
+		}
+		seen[target.URL] = true
+		names[target.Name] = true
+		cleaned = append(cleaned, target)
+		if len(cleaned) == maxTargets {
+			break
+		}
+	}
+	return cleaned
+}
+
+// scrapeTargets scrapes Config.URL and every extra target and merges them.
+// A bug listed by several targets is counted once and carries all their
+// names in Targets; Stats.Targets keeps each page's own counts. Any target
+// failing fails the whole sync, so a missing page never reads as bugs
+// being fixed.
+func (m *Monitor) scrapeTargets(ctx context.Context, cfg Config) (Stats, []Bug, int, error) {
+	pages := append([]Target{{Name: primaryTargetName, URL: cfg.URL}}, cfg.Targets...)
+	var merged Stats
+	var bugs []Bug
+	index := map[int]int{}
+	status := 0
+	for _, target := range pages {
+		pageCfg := cfg
+		pageCfg.URL = target.URL
+		doc, pageStatus, err := m.FetchPage(ctx, pageCfg)
+		status = pageStatus
+		if err != nil {
+			return Stats{}, nil, status, fmt.Errorf("target %s: %w", target.Name, err)
+		}
+		stats := ParseStats(doc)
+		merged.Targets = append(merged.Targets, TargetStats{
+			Name:     target.Name,
+			URL:      target.URL,
+			Total:    stats.Total,
+			Severity: stats.Severity,
+		})
+		merged.Total += stats.Total
+		addSeverity(&merged.Severity, stats.Severity, 1)
+		for _, bug := range ParseBugs(doc, target.URL) {
+			if i, ok := index[bug.ID]; ok {
+				bugs[i].Targets = append(bugs[i].Targets, target.Name)
+				merged.Total--
+				addSeverity(&merged.Severity, severityCount(bug.Severity), -1)
+				continue
+			}
+			bug.Targets = []string{target.Name}
+			index[bug.ID] = len(bugs)
+			bugs = append(bugs, bug)
+		}
+	}
+	merged.LastUpdated = time.Now()
+	if bugs == nil {
+		bugs = []Bug{}
+	}
+	return merged, bugs, status, nil
+}
+
+func severityCount(severity string) SeverityCounts {
+	var counts SeverityCounts
+	switch severity {
+	case "critical":
+		counts.Critical = 1
+	case "severe":
+		counts.Severe = 1
+	case "major":
+		counts.Major = 1
+	case "minor":
+		counts.Minor = 1
+	}
+	return counts
+}
+
+func addSeverity(total *SeverityCounts, counts SeverityCounts, sign int) {
+	total.Critical += sign * counts.Critical
+	total.Severe += sign * counts.Severe
+	total.Major += sign * counts.Major
+	total.Minor += sign * counts.Minor
+}
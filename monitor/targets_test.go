@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScrapeTargetsMergesAndLabels(t *testing.T) {
+	z := newFakeZenTao(t)
+	cfg := defaultConfig()
+	cfg.URL = z.serve("/zentao/my-work-bug.html", bugListPage(
+		fakeBug{ID: 1, Severity: 1, Title: "登录页白屏"},
+		fakeBug{ID: 2, Severity: 3, Title: "按钮错位"},
+	))
+	cfg.Targets = sanitizeTargets([]Target{
+		{Name: "商城", URL: z.serve("/zentao/bug-browse-3.html", bugListPage(
+			fakeBug{ID: 2, Severity: 3, Title: "按钮错位"},
+			fakeBug{ID: 3, Severity: 2, Title: "支付超时"},
+		))},
+		{URL: cfg.URL},
+		{URL: " "},
+	}, cfg.URL)
+	if len(cfg.Targets) != 1 {
+		t.Fatalf("sanitized targets = %+v, want only 商城", cfg.Targets)
+	}
+
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	stats, bugs, _, err := m.scrape(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 3 || stats.Severity != (SeverityCounts{Critical: 1, Severe: 1, Major: 1}) {
+		t.Errorf("merged stats = %d %+v, want 3 bugs, one of each of levels 1-3", stats.Total, stats.Severity)
+	}
+	wantTargets := []TargetStats{
+		{Name: primaryTargetName, URL: cfg.URL, Total: 2, Severity: SeverityCounts{Critical: 1, Major: 1}},
+		{Name: "商城", URL: cfg.Targets[0].URL, Total: 2, Severity: SeverityCounts{Severe: 1, Major: 1}},
+	}
+	if !reflect.DeepEqual(stats.Targets, wantTargets) {
+		t.Errorf("per-target stats = %+v, want %+v", stats.Targets, wantTargets)
+	}
+	labels := map[int][]string{}
+	for _, bug := range bugs {
+		labels[bug.ID] = bug.Targets
+	}
+	wantLabels := map[int][]string{1: {primaryTargetName}, 2: {primaryTargetName, "商城"}, 3: {"商城"}}
+	if !reflect.DeepEqual(labels, wantLabels) {
+		t.Errorf("bug targets = %v, want %v", labels, wantLabels)
+	}
+
+	cfg.Targets = append(cfg.Targets, Target{Name: "过期", URL: z.serve("/zentao/bug-browse-4.html", loginPage)})
+	if _, _, _, err := m.scrape(context.Background(), cfg); !errors.Is(err, ErrLoginRequired) {
+		t.Errorf("scrape with a logged-out target = %v, want ErrLoginRequired", err)
+	}
+}
+
+func TestMetricsPerTarget(t *testing.T) {
+	m := New(Options{DataDir: t.TempDir(), Headless: true})
+	m.config.URL = "http://zentao.example.com/my-work-bug.html"
+	render := func(stats Stats) string {
+		m.mu.Lock()
+		m.stats = stats
+		m.mu.Unlock()
+		var out strings.Builder
+		m.writeMetrics(&out)
+		return out.String()
+	}
+
+	single := render(Stats{Total: 2, Actionable: 2, Severity: SeverityCounts{Critical: 2}})
+	for _, want := range []string{
+		`bugdog_bugs{target="http://zentao.example.com/my-work-bug.html",severity="critical"} 2`,
+		`bugdog_bugs_open{target="http://zentao.example.com/my-work-bug.html"} 2`,
+		`bugdog_bugs_actionable{target="http://zentao.example.com/my-work-bug.html"} 2`,
+	} {
+		if !strings.Contains(single, want) {
+			t.Errorf("single-target metrics missing %s", want)
+		}
+	}
+
+	multi := render(Stats{Total: 3, Actionable: 3, Targets: []TargetStats{
+		{Name: primaryTargetName, Total: 2, Severity: SeverityCounts{Critical: 1, Major: 1}},
+		{Name: "商城", Total: 2, Severity: SeverityCounts{Severe: 1, Major: 1}},
+	}})
+	for _, want := range []string{
+		`bugdog_bugs{target="default",severity="critical"} 1`,
+		`bugdog_bugs{target="商城",severity="severe"} 1`,
+		`bugdog_bugs_open{target="default"} 2`,
+		`bugdog_bugs_open{target="商城"} 2`,
+		"bugdog_bugs_actionable 3",
+	} {
+		if !strings.Contains(multi, want) {
+			t.Errorf("multi-target metrics missing %s", want)
+		}
+	}
+	if strings.Contains(multi, "my-work-bug.html\",severity") {
+		t.Error("multi-target metrics still report the merged counts under the main URL")
+	}
+}
@@ -0,0 +1,151 @@
+package monitor
+
+import "time"
+
+type Config struct {
+	URL                 string          `json:"url"`
+	Targets             []Target        `json:"targets"`
+	Cookie              string          `json:"cookie"`
+	IntervalMinutes     int             `json:"intervalMinutes"`
+	EnableNotifications bool            `json:"enableNotifications"`
+	EnableSound         bool            `json:"enableSound"`
+	NotifyLevels        map[string]bool `json:"notifyLevels"`
+	NotifyOnIncrease    bool            `json:"notifyOnIncrease"`
+	NotifyOnDecrease    bool            `json:"notifyOnDecrease"`
+	SecretBackend       string          `json:"secretBackend"`
+	Source              string          `json:"source"`
+	RelayURL            string          `json:"relayUrl"`
+	RelayToken          string          `json:"relayToken"`
+	APIEnabled          bool            `json:"apiEnabled"`
+	APIHost             string          `json:"apiHost"`
+	APIPort             int             `json:"apiPort"`
+	APIToken            string          `json:"apiToken"`
+	SubscriberToken     string          `json:"subscriberToken"`
+	WebhookEnabled      bool            `json:"webhookEnabled"`
+	WebhookAddr         string          `json:"webhookAddr"`
+	WebhookSecret       string          `json:"webhookSecret"`
+	ExecHooks           []ExecHook      `json:"execHooks"`
+	Watchlist           []int           `json:"watchlist"`
+	IgnoreRules         []IgnoreRule    `json:"ignoreRules"`
+}
+
+// ExecHook runs Command with Args on the hook events named in Events (all
+// of them when empty), killing it after TimeoutSeconds.
+type ExecHook struct {
+	Name           string   `json:"name"`
+	Command        string   `json:"command"`
+	Args           []string `json:"args"`
+	Events         []string `json:"events"`
+	TimeoutSeconds int      `json:"timeoutSeconds"`
+}
+
+type SeverityCounts struct {
+	Critical int `json:"critical"`
+	Severe   int `json:"severe"`
+	Major    int `json:"major"`
+	Minor    int `json:"minor"`
+}
+
+// Stats counts the bug list. Total and Severity are the raw counts;
+// Actionable and ActionableSeverity leave out the Muted bugs matched by
+// ignore rules, and are what notifications follow. Unread counts the bugs
+// not looked at since they first appeared. Targets breaks Total and
+// Severity down by page when Config.Targets adds more than one.
+type Stats struct {
+	Total              int            `json:"total"`
+	Severity           SeverityCounts `json:"severity"`
+	Actionable         int            `json:"actionable"`
+	ActionableSeverity SeverityCounts `json:"actionableSeverity"`
+	Muted              int            `json:"muted"`
+	Unread             int            `json:"unread"`
+	Targets            []TargetStats  `json:"targets,omitempty"`
+	LastUpdated        time.Time      `json:"lastUpdated"`
+}
+
+// Change log entry kinds: a change in the list stats, or a change to one
+// watched bug described by BugID and Message.
+const (
+	ChangeKindStats = "stats"
+	ChangeKindWatch = "watch"
+)
+
+type ChangeLogEntry struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Kind      string         `json:"kind"`
+	Total     int            `json:"total"`
+	Delta     int            `json:"delta"`
+	Severity  SeverityCounts `json:"severity"`
+	BugID     int            `json:"bugId,omitempty"`
+	Message   string         `json:"message,omitempty"`
+}
+
+// LogEntry is one line of the activity log. Level is "info", "notify" (a
+// notification was shown or, headless, logged instead), "warn" (something
+// went wrong but was handled, such as a file recovered from its backup) or
+// "error".
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Status    int       `json:"status"`
+	Message   string    `json:"message"`
+	Output    string    `json:"output,omitempty"`
+}
+
+type State struct {
+	LastStats Stats `json:"lastStats"`
+	LastBugs  []Bug `json:"lastBugs,omitempty"`
+}
+
+// Health summarises the most recent sync attempts.
+type Health struct {
+	Status       string    `json:"status"`
+	Monitoring   bool      `json:"monitoring"`
+	LastAttempt  time.Time `json:"lastAttempt"`
+	LastSuccess  time.Time `json:"lastSuccess"`
+	LastError    string    `json:"lastError,omitempty"`
+	ErrorKind    string    `json:"errorKind,omitempty"`
+	SnoozedUntil time.Time `json:"snoozedUntil"`
+}
+
+// HistoryRecord is one scrape. After compaction a record also stands for
+// the identical samples that followed it within the hour: Samples, Failures
+// and AvgDurationMs then describe that whole run.
+type HistoryRecord struct {
+	Timestamp     time.Time      `json:"timestamp"`
+	Status        string         `json:"status"`
+	HTTPStatus    int            `json:"httpStatus,omitempty"`
+	DurationMs    int64          `json:"durationMs"`
+	Total         int            `json:"total"`
+	Severity      SeverityCounts `json:"severity"`
+	Error         string         `json:"error,omitempty"`
+	Samples       int            `json:"samples,omitempty"`
+	Failures      int            `json:"failures,omitempty"`
+	AvgDurationMs int64          `json:"avgDurationMs,omitempty"`
+}
+
+type HistoryPoint struct {
+	Start         time.Time      `json:"start"`
+	Samples       int            `json:"samples"`
+	Failures      int            `json:"failures"`
+	Total         int            `json:"total"`
+	MinTotal      int            `json:"minTotal"`
+	MaxTotal      int            `json:"maxTotal"`
+	Severity      SeverityCounts `json:"severity"`
+	AvgDurationMs int64          `json:"avgDurationMs"`
+}
+
+type Bug struct {
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Severity   string    `json:"severity"`
+	Priority   string    `json:"priority"`
+	Status     string    `json:"status"`
+	Module     string    `json:"module"`
+	OpenedBy   string    `json:"openedBy"`
+	AssignedTo string    `json:"assignedTo"`
+	URL        string    `json:"url"`
+	Muted      bool      `json:"muted,omitempty"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	Unread     bool      `json:"unread,omitempty"`
+	Targets    []string  `json:"targets,omitempty"`
+}
@@ -0,0 +1,283 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	watchFileName    = "watchlist.json"
+	maxWatchlist     = 50
+	watchPollTimeout = 2 * time.Minute
+)
+
+var watchSchema = fileSchema{
+	name: watchFileName,
+	migrations: []migration{
+		stampVersion,
+	},
+}
+
+// WatchedBug is the last seen state of a bug on the watchlist. CheckedAt is
+// zero until the first successful check; Error holds the latest failure.
+type WatchedBug struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Status      string    `json:"status"`
+	AssignedTo  string    `json:"assignedTo"`
+	Severity    string    `json:"severity"`
+	Comments    int       `json:"comments"`
+	LastComment string    `json:"lastComment"`
+	CheckedAt   time.Time `json:"checkedAt"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type watchFile struct {
+	Bugs []WatchedBug `json:"bugs"`
+}
+
+func sanitizeWatchlist(ids []int) []int {
+	cleaned := make([]int, 0, len(ids))
+	seen := map[int]bool{}
+	for _, id := range ids {
+		if id < 1 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		cleaned = append(cleaned, id)
+		if len(cleaned) == maxWatchlist {
+			break
+		}
+	}
+	return cleaned
+}
+
+// GetWatchedBugs returns the watchlist in order with each bug's last seen
+// state.
+func (m *Monitor) GetWatchedBugs() []WatchedBug {
+	ids := m.currentConfig().Watchlist
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bugs := make([]WatchedBug, 0, len(ids))
+	for _, id := range ids {
+		bug, ok := m.watched[id]
+		if !ok {
+			bug = WatchedBug{ID: id}
+		}
+		bugs = append(bugs, bug)
+	}
+	return bugs
+}
+
+// WatchBug adds id to the watchlist. Its state is recorded on the next sync
+// and changes are reported from then on.
+func (m *Monitor) WatchBug(id int) error {
+	cfg := m.currentConfig()
+	for _, existing := range cfg.Watchlist {
+		if existing == id {
+			return nil
+		}
+	}
+	if len(cfg.Watchlist) >= maxWatchlist {
+		return fmt.Errorf("关注列表最多 %d 个缺陷", maxWatchlist)
+	}
+	cfg.Watchlist = append(append([]int(nil), cfg.Watchlist...), id)
+	return m.SaveConfig(cfg)
+}
+
+func (m *Monitor) UnwatchBug(id int) error {
+	cfg := m.currentConfig()
+	kept := make([]int, 0, len(cfg.Watchlist))
+	for _, existing := range cfg.Watchlist {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+	cfg.Watchlist = kept
+	return m.SaveConfig(cfg)
+}
+
+func (m *Monitor) loadWatchlist() error {
+	var file watchFile
+	if err := m.readVersioned(watchSchema, &file); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.watched = make(map[int]WatchedBug, len(file.Bugs))
+	for _, bug := range file.Bugs {
+		m.watched[bug.ID] = bug
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// startWatchPoll checks the watchlist in the background once a sync has
+// finished, whether or not the list scrape worked, so FetchNow and /sync
+// never wait on the bug pages. A check still running is not started twice.
+func (m *Monitor) startWatchPoll() {
+	cfg := m.currentConfig()
+	if len(cfg.Watchlist) == 0 {
+		return
+	}
+	m.mu.Lock()
+	if m.watchPolling {
+		m.mu.Unlock()
+		return
+	}
+	m.watchPolling = true
+	m.mu.Unlock()
+
+	parent := m.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(parent, watchPollTimeout)
+		defer cancel()
+		m.pollWatchlist(ctx, cfg)
+		m.mu.Lock()
+		m.watchPolling = false
+		m.mu.Unlock()
+	}()
+}
+
+// pollWatchlist checks every watched bug past the detail cache and records
+// a change log entry, runs the change hooks and notifies for each one whose
+// status, assignee, severity or comments changed. The first check of a bug
+// only records its state.
+func (m *Monitor) pollWatchlist(ctx context.Context, cfg Config) {
+	stats := m.GetStats()
+	next := make(map[int]WatchedBug, len(cfg.Watchlist))
+	var changes []ChangeLogEntry
+	for _, id := range cfg.Watchlist {
+		m.mu.Lock()
+		previous, known := m.watched[id]
+		m.mu.Unlock()
+
+		detail, err := m.refreshBugDetail(ctx, id)
+		if err != nil {
+			previous.ID = id
+			previous.Error = err.Error()
+			next[id] = previous
+			m.addLog("warn", fmt.Sprintf("Checking watched bug #%d failed: %v", id, err), 0)
+			continue
+		}
+		current := watchedFromDetail(detail)
+		current.ID = id
+		next[id] = current
+		if !known || previous.CheckedAt.IsZero() {
+			continue
+		}
+		if diff := diffWatched(previous, current); diff != "" {
+			changes = append(changes, ChangeLogEntry{
+				Timestamp: current.CheckedAt,
+				Kind:      ChangeKindWatch,
+				Total:     stats.Total,
+				Severity:  stats.Severity,
+				BugID:     id,
+				Message:   fmt.Sprintf("#%d %s：%s", id, truncateText(current.Title, previewLineRunes), diff),
+			})
+		}
+	}
+
+	m.mu.Lock()
+	m.watched = next
+	m.mu.Unlock()
+	m.saveWatchlist(cfg.Watchlist, next)
+	m.emitWatchlist()
+
+	for _, entry := range changes {
+		m.addLog("info", "Watched bug changed: "+entry.Message, 0)
+		m.addChangeLog(entry)
+		m.runHooks(HookEvent{
+			Type:     HookEventChange,
+			Message:  entry.Message,
+			Total:    stats.Total,
+			Severity: stats.Severity,
+			Change:   &entry,
+		})
+	}
+	if len(changes) > 0 {
+		m.emitChangeLog()
+		messages := make([]string, len(changes))
+		for i, entry := range changes {
+			messages[i] = entry.Message
+		}
+		m.maybeNotifyChange("关注的缺陷有更新：\n"+strings.Join(messages, "\n"), nil)
+	}
+}
+
+func (m *Monitor) saveWatchlist(ids []int, watched map[int]WatchedBug) {
+	file := watchFile{Bugs: make([]WatchedBug, 0, len(ids))}
+	for _, id := range ids {
+		if bug, ok := watched[id]; ok {
+			file.Bugs = append(file.Bugs, bug)
+		}
+	}
+	if err := m.writeVersioned(watchSchema, file); err != nil {
+		m.addLog("error", fmt.Sprintf("Saving %s failed: %v", watchFileName, err), 0)
+	}
+}
+
+func watchedFromDetail(detail BugDetail) WatchedBug {
+	bug := WatchedBug{
+		Title:      detail.Title,
+		URL:        detail.URL,
+		Status:     detail.Status,
+		AssignedTo: detail.AssignedTo,
+		Severity:   detail.Severity,
+		Comments:   len(detail.Comments),
+		CheckedAt:  detail.FetchedAt,
+	}
+	if bug.CheckedAt.IsZero() {
+		bug.CheckedAt = time.Now()
+	}
+	if len(detail.Comments) > 0 {
+		bug.LastComment = detail.Comments[len(detail.Comments)-1]
+	}
+	return bug
+}
+
+// diffWatched describes what changed between two checks of a bug, or
+// returns "" when nothing it tracks did.
+func diffWatched(previous, current WatchedBug) string {
+	var parts []string
+	if previous.Status != current.Status {
+		parts = append(parts, fmt.Sprintf("状态 %s→%s", orDash(previous.Status), orDash(current.Status)))
+	}
+	if previous.AssignedTo != current.AssignedTo {
+		parts = append(parts, fmt.Sprintf("指派 %s→%s", orDash(previous.AssignedTo), orDash(current.AssignedTo)))
+	}
+	if previous.Severity != current.Severity {
+		parts = append(parts, fmt.Sprintf("严重程度 %s→%s", severityName(previous.Severity), severityName(current.Severity)))
+	}
+	if current.Comments > previous.Comments {
+		comment := strings.ReplaceAll(stripMarkdown(current.LastComment), "\n", " ")
+		parts = append(parts, "新评论："+truncateText(comment, previewLineRunes))
+	}
+	return strings.Join(parts, "；")
+}
+
+func severityName(severity string) string {
+	switch severity {
+	case "critical":
+		return "一级"
+	case "severe":
+		return "二级"
+	case "major":
+		return "三级"
+	case "minor":
+		return "四级"
+	}
+	return "—"
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
@@ -0,0 +1,44 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const bugViewPage = `<html><head><title>BUG #7 导出白屏 - 禅道</title></head><body>` +
+	`<div class="page-title"><span class="text" title="导出白屏">导出白屏</span></div>` +
+	`<table><tr><th>Bug状态</th><td>激活</td></tr><tr><th>当前指派</th><td>张三</td></tr></table></body></html>`
+
+func TestWatchlistPolledAfterFailedSync(t *testing.T) {
+	z := newFakeZenTao(t)
+	url := z.serve("/zentao/my-work-bug.html", loginPage)
+	z.serve("/zentao/bug-view-7.html", bugViewPage)
+	release := z.hold(t, "/zentao/bug-view-7.html")
+	m, sink := newTestMonitor(t, t.TempDir(), url)
+	m.config.Watchlist = []int{7}
+
+	synced := make(chan error, 1)
+	go func() { synced <- m.FetchNow() }()
+	select {
+	case err := <-synced:
+		if !errors.Is(err, ErrLoginRequired) {
+			t.Fatalf("FetchNow = %v, want ErrLoginRequired", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("FetchNow is waiting for the watchlist check")
+	}
+
+	release()
+	deadline := time.Now().Add(10 * time.Second)
+	for len(sink.Named(EventWatchlist)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the watchlist was not checked after the failed sync")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	watched := lastPayload[[]WatchedBug](t, sink, EventWatchlist)
+	if len(watched) != 1 || watched[0].Title != "导出白屏" || watched[0].Status != "激活" || watched[0].CheckedAt.IsZero() {
+		t.Errorf("watched bugs = %+v, want #7 checked", watched)
+	}
+}
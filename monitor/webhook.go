@@ -0,0 +1,194 @@
+package monitor
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultWebhookAddr only accepts posts from this machine. Like APIHost,
+	// listening on the LAN for a ZenTao server elsewhere is opt-in.
+	defaultWebhookAddr = "127.0.0.1:47832"
+	// legacyWebhookAddr was the default before schema v2.
+	legacyWebhookAddr = "0.0.0.0:47832"
+	maxWebhookBody    = 1 << 20
+	// webhookRejectLogEvery limits rejected posts to one log entry per
+	// remote host per interval, so a misconfigured or hostile sender cannot
+	// flood the log.
+	webhookRejectLogEvery = time.Minute
+)
+
+// zentaoEvent is the JSON body ZenTao posts for the "default" webhook type.
+type zentaoEvent struct {
+	ObjectType string     `json:"objectType"`
+	ObjectID   flexibleID `json:"objectID"`
+	Action     string     `json:"action"`
+	Actor      string     `json:"actor"`
+	Text       string     `json:"text"`
+}
+
+// flexibleID accepts an id sent either as a number or as a string, since
+// ZenTao versions differ.
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	*id = flexibleID(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// GetWebhookSecret returns the token ZenTao must append to the webhook URL.
+func (m *Monitor) GetWebhookSecret() string {
+	return m.currentConfig().WebhookSecret
+}
+
+// startWebhook (re)starts the webhook receiver to match the current config,
+// or stops it when webhooks are disabled.
+func (m *Monitor) startWebhook() {
+	m.stopWebhook()
+	cfg := m.currentConfig()
+	if !cfg.WebhookEnabled {
+		return
+	}
+	if err := m.ensureToken("webhook secret", func(c *Config) *string { return &c.WebhookSecret }); err != nil {
+		return
+	}
+	server, err := m.listenHTTP("Webhook receiver", cfg.WebhookAddr, m.webhookHandler())
+	if err != nil {
+		return
+	}
+	m.mu.Lock()
+	m.webhook = server
+	m.mu.Unlock()
+}
+
+func (m *Monitor) stopWebhook() {
+	m.mu.Lock()
+	server := m.webhook
+	m.webhook = nil
+	m.mu.Unlock()
+	m.closeHTTP(server)
+}
+
+// webhookHandler accepts ZenTao webhook posts on /zentao. ZenTao cannot
+// sign its requests, so the secret travels in the URL configured there
+// (?token=...) or in an X-BugDog-Webhook-Token header added by a proxy.
+// A bug event triggers an immediate sync; polling carries on regardless, so
+// a missed or rejected webhook is reconciled on the next interval.
+func (m *Monitor) webhookHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /zentao", func(w http.ResponseWriter, r *http.Request) {
+		secret := m.currentConfig().WebhookSecret
+		given := r.Header.Get("X-BugDog-Webhook-Token")
+		if given == "" {
+			given = r.URL.Query().Get("token")
+		}
+		if secret == "" || subtle.ConstantTimeCompare([]byte(given), []byte(secret)) != 1 {
+			m.logWebhookReject(r, "bad token", http.StatusUnauthorized)
+			writeAPIError(w, http.StatusUnauthorized, "invalid or missing webhook token")
+			return
+		}
+
+		event, err := decodeZentaoEvent(r)
+		if err != nil {
+			m.logWebhookReject(r, err.Error(), http.StatusBadRequest)
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !strings.EqualFold(event.ObjectType, "bug") {
+			writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "ignored"})
+			return
+		}
+		m.addLog("info", fmt.Sprintf("Webhook: bug #%s %s by %s, syncing now", event.ObjectID, event.Action, event.Actor), 0)
+		m.TriggerSync()
+		writeAPIJSON(w, http.StatusAccepted, map[string]string{"status": "queued"})
+	})
+	return mux
+}
+
+// webhookRejects tracks, per remote host, when a rejected post was last
+// logged and how many have been dropped since.
+type webhookRejects map[string]*webhookReject
+
+type webhookReject struct {
+	logged     time.Time
+	suppressed int
+}
+
+// logWebhookReject logs a rejected post unless the same host already had
+// one logged within webhookRejectLogEvery; those are counted and reported
+// with the next entry instead.
+func (m *Monitor) logWebhookReject(r *http.Request, reason string, status int) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	now := time.Now()
+	m.mu.Lock()
+	if m.webhookRejects == nil {
+		m.webhookRejects = webhookRejects{}
+	}
+	for key, reject := range m.webhookRejects {
+		if now.Sub(reject.logged) >= webhookRejectLogEvery && reject.suppressed == 0 {
+			delete(m.webhookRejects, key)
+		}
+	}
+	reject := m.webhookRejects[host]
+	if reject != nil && now.Sub(reject.logged) < webhookRejectLogEvery {
+		reject.suppressed++
+		m.mu.Unlock()
+		return
+	}
+	suppressed := 0
+	if reject != nil {
+		suppressed = reject.suppressed
+	}
+	m.webhookRejects[host] = &webhookReject{logged: now}
+	m.mu.Unlock()
+
+	message := fmt.Sprintf("Webhook from %s rejected: %s", host, reason)
+	if suppressed > 0 {
+		message += fmt.Sprintf(" (%d more rejected since the last entry)", suppressed)
+	}
+	m.addLog("warn", message, status)
+}
+
+// decodeZentaoEvent reads a webhook body. ZenTao posts JSON, but older
+// versions send the same fields form-encoded.
+func decodeZentaoEvent(r *http.Request) (zentaoEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		return zentaoEvent{}, err
+	}
+	var event zentaoEvent
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return zentaoEvent{}, err
+		}
+		event = zentaoEvent{
+			ObjectType: form.Get("objectType"),
+			ObjectID:   flexibleID(form.Get("objectID")),
+			Action:     form.Get("action"),
+			Actor:      form.Get("actor"),
+			Text:       form.Get("text"),
+		}
+	} else if err := json.Unmarshal(body, &event); err != nil {
+		return zentaoEvent{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if event.ObjectType == "" {
+		return zentaoEvent{}, errors.New("missing objectType")
+	}
+	return event, nil
+}
+
+func webhookConfigChanged(previous, cfg Config) bool {
+	return previous.WebhookEnabled != cfg.WebhookEnabled || previous.WebhookAddr != cfg.WebhookAddr
+}
@@ -0,0 +1,97 @@
+package monitor
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBug is one row of a fake ZenTao bug list; Severity is ZenTao's 1-4.
+type fakeBug struct {
+	ID       int
+	Severity int
+	Title    string
+	Status   string
+	Module   string
+	OpenedBy string
+}
+
+// bugListPage renders a "my bugs" page the way ZenTao's PATH_INFO install
+// does, with the total in the table footer.
+func bugListPage(bugs ...fakeBug) string {
+	var rows strings.Builder
+	for _, bug := range bugs {
+		fmt.Fprintf(&rows, `<tr><td class="c-id">%d</td><td class="c-pri">3</td><td class="c-severity"><span>%d</span></td>`+
+			`<td class="c-title"><a href="/zentao/bug-view-%d.html" title="%s">%s</a></td>`+
+			`<td class="c-status">%s</td><td class="c-module">%s</td><td class="c-openedBy">%s</td><td class="c-assignedTo">我</td></tr>`,
+			bug.ID, bug.Severity, bug.ID, html.EscapeString(bug.Title), html.EscapeString(bug.Title),
+			bug.Status, bug.Module, bug.OpenedBy)
+	}
+	return `<html><body><table id="bugList"><thead><tr><th>ID</th><th>P</th><th>严重程度</th><th>标题</th>` +
+		`<th>状态</th><th>模块</th><th>创建者</th><th>指派给</th></tr></thead><tbody>` + rows.String() +
+		`</tbody></table><div class="table-footer">共 ` + fmt.Sprint(len(bugs)) + ` 项</div></body></html>`
+}
+
+// loginPage is what ZenTao serves in place of any page once the session
+// cookie has expired.
+const loginPage = `<html><body><form method="post" action="/zentao/user-login.html">` +
+	`<input type="text" name="account"><input type="password" name="password"></form></body></html>`
+
+// fakeZenTao serves HTML pages by path. Pages can be swapped while it runs,
+// and held so requests for them block until released.
+type fakeZenTao struct {
+	*httptest.Server
+	mu      sync.Mutex
+	pages   map[string]string
+	held    map[string]chan struct{}
+	cookies []string
+}
+
+func newFakeZenTao(t *testing.T) *fakeZenTao {
+	t.Helper()
+	z := &fakeZenTao{pages: map[string]string{}, held: map[string]chan struct{}{}}
+	z.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		z.mu.Lock()
+		z.cookies = append(z.cookies, r.Header.Get("Cookie"))
+		held := z.held[r.URL.Path]
+		z.mu.Unlock()
+		if held != nil {
+			<-held
+		}
+		z.mu.Lock()
+		page, ok := z.pages[r.URL.Path]
+		z.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	}))
+	t.Cleanup(z.Close)
+	return z
+}
+
+func (z *fakeZenTao) serve(path, page string) string {
+	z.mu.Lock()
+	z.pages[path] = page
+	z.mu.Unlock()
+	return z.URL + path
+}
+
+// hold makes requests for path wait until the returned release is called.
+// Held requests are released when the test ends.
+func (z *fakeZenTao) hold(t *testing.T, path string) (release func()) {
+	ch := make(chan struct{})
+	z.mu.Lock()
+	z.held[path] = ch
+	z.mu.Unlock()
+	var once sync.Once
+	release = func() { once.Do(func() { close(ch) }) }
+	t.Cleanup(release)
+	return release
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const settingsFileName = "gui.json"
+
+// Browser choices for opening bug links. browserCustom runs BrowserCommand,
+// with {url} replaced by the link.
+const (
+	browserDefault  = "default"
+	browserChrome   = "chrome"
+	browserChromium = "chromium"
+	browserEdge     = "edge"
+	browserFirefox  = "firefox"
+	browserCustom   = "custom"
+)
+
+// Badge modes for the tray icon: the number of bugs in the list, or only
+// the ones not looked at yet.
+const (
+	badgeTotal  = "total"
+	badgeUnread = "unread"
+)
+
+// Settings are the desktop app's own preferences, kept in gui.json. The
+// monitor core and the headless commands never look at them.
+type Settings struct {
+	AutoStart      bool   `json:"autoStart"`
+	Browser        string `json:"browser"`
+	BrowserCommand string `json:"browserCommand"`
+	BadgeMode      string `json:"badgeMode"`
+}
+
+func defaultSettings() Settings {
+	return Settings{Browser: browserDefault, BadgeMode: badgeTotal}
+}
+
+func sanitizeSettings(settings Settings) Settings {
+	settings.BrowserCommand = strings.TrimSpace(settings.BrowserCommand)
+	switch settings.Browser {
+	case browserChrome, browserChromium, browserEdge, browserFirefox:
+	case browserCustom:
+		if settings.BrowserCommand == "" {
+			settings.Browser = browserDefault
+		}
+	default:
+		settings.Browser = browserDefault
+	}
+	if settings.BadgeMode != badgeUnread {
+		settings.BadgeMode = badgeTotal
+	}
+	return settings
+}
+
+// loadSettings reads gui.json. Before it existed these settings lived in
+// config.json, so the first start after upgrading copies them from there;
+// it has to run before the monitor loads, and possibly rewrites, the config.
+func (a *App) loadSettings() {
+	settings := defaultSettings()
+	err := a.monitor.LoadFile(settingsFileName, &settings)
+	if errors.Is(err, fs.ErrNotExist) {
+		settings = defaultSettings()
+		if data, readErr := os.ReadFile(a.monitor.ConfigPath()); readErr == nil {
+			_ = json.Unmarshal(data, &settings)
+		}
+		settings = sanitizeSettings(settings)
+		if err := a.monitor.SaveFile(settingsFileName, settings); err != nil {
+			a.monitor.Log("error", "Saving GUI settings failed: "+err.Error())
+		}
+	} else if err != nil {
+		settings = defaultSettings()
+	}
+	a.mu.Lock()
+	a.settings = sanitizeSettings(settings)
+	a.mu.Unlock()
+}
+
+func (a *App) currentSettings() Settings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings
+}
+
+func (a *App) GetSettings() Settings {
+	return a.currentSettings()
+}
+
+// SaveSettings stores the desktop preferences, registering or removing the
+// login entry when AutoStart changes.
+func (a *App) SaveSettings(settings Settings) error {
+	settings = sanitizeSettings(settings)
+	previous := a.currentSettings()
+	if settings.AutoStart != previous.AutoStart {
+		if err := a.applyAutoStart(settings.AutoStart); err != nil {
+			return err
+		}
+		if settings.AutoStart {
+			a.monitor.Log("info", "Autostart enabled")
+		} else {
+			a.monitor.Log("info", "Autostart disabled")
+		}
+	}
+	if err := a.monitor.SaveFile(settingsFileName, settings); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.settings = settings
+	a.mu.Unlock()
+	a.updateTray()
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, "settings", settings)
+	}
+	return nil
+}
@@ -45,28 +45,49 @@ func (a *App) onTrayReady() {
 	systray.SetIcon(trayIconICO)
 	systray.SetTitle("禅道监控")
 	systray.SetTooltip("禅道监控")
+	menu := a.buildTrayMenu()
+	a.mu.Lock()
+	a.trayReady = true
+	a.trayMenu = menu
+	a.trayIcon = trayIconState{}
+	a.trayTooltip = ""
+	a.mu.Unlock()
+	a.updateTray()
+}
 
-	toggleItem := systray.AddMenuItem("显示/隐藏", "切换主窗口")
-	syncItem := systray.AddMenuItem("立即同步", "抓取最新缺陷")
-	systray.AddSeparator()
-	quitItem := systray.AddMenuItem("退出", "退出应用")
-
-	go func() {
-		for {
-			select {
-			case <-toggleItem.ClickedCh:
-				a.toggleWindow()
-			case <-syncItem.ClickedCh:
-				_ = a.FetchNow()
-			case <-quitItem.ClickedCh:
-				a.quitFromTray()
-				return
-			}
-		}
-	}()
+func (a *App) onTrayExit() {
+	a.mu.Lock()
+	a.trayReady = false
+	a.trayMenu = nil
+	a.mu.Unlock()
 }
 
-func (a *App) onTrayExit() {}
+// updateTray redraws the icon, tooltip and menu from the monitor's current
+// stats and health, skipping the icon when nothing visible changed.
+func (a *App) updateTray() {
+	stats := a.monitor.GetStats()
+	health := a.monitor.GetHealth()
+	bugs := a.monitor.GetBugs()
+	state, tooltip := trayState(stats, bugs, health, a.currentSettings().BadgeMode)
+	a.mu.Lock()
+	if !a.trayReady {
+		a.mu.Unlock()
+		return
+	}
+	menu := a.trayMenu
+	iconChanged := state != a.trayIcon
+	tooltipChanged := tooltip != a.trayTooltip
+	a.trayIcon = state
+	a.trayTooltip = tooltip
+	a.mu.Unlock()
+	if iconChanged {
+		systray.SetIcon(renderTrayIcon(state))
+	}
+	if tooltipChanged {
+		systray.SetTooltip(tooltip)
+	}
+	menu.refresh(stats, bugs, health)
+}
 
 func (a *App) toggleWindow() {
 	if a.ctx == nil {
@@ -76,20 +97,34 @@ func (a *App) toggleWindow() {
 	hidden := a.windowHidden
 	a.mu.Unlock()
 	if hidden {
-		wailsRuntime.WindowShow(a.ctx)
-		wailsRuntime.WindowCenter(a.ctx)
-		a.setWindowHidden(false)
+		a.showWindow()
 		return
 	}
+	a.saveWindowState()
 	wailsRuntime.WindowHide(a.ctx)
 	a.setWindowHidden(true)
 }
 
+func (a *App) showWindow() {
+	a.mu.Lock()
+	hidden := a.windowHidden
+	a.mu.Unlock()
+	if hidden {
+		a.restoreWindowState()
+	}
+	wailsRuntime.WindowShow(a.ctx)
+	wailsRuntime.WindowUnminimise(a.ctx)
+	a.setWindowHidden(false)
+	// The window marks the unread bugs seen once it has listed them.
+	wailsRuntime.EventsEmit(a.ctx, "window-shown")
+}
+
 func (a *App) quitFromTray() {
 	a.mu.Lock()
 	a.quitting = true
 	a.mu.Unlock()
 	if a.ctx != nil {
+		a.saveWindowState()
 		wailsRuntime.Quit(a.ctx)
 	}
 }
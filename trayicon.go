@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	goRuntime "runtime"
+	"strconv"
+	"sync"
+
+	"bugDog_V2/monitor"
+)
+
+const trayIconSize = 64
+
+// Tray icon modes. Normal shows the bug count badge; the others replace it.
+const (
+	trayModeNormal = "normal"
+	trayModePaused = "paused"
+	trayModeError  = "error"
+	trayModeAuth   = "auth"
+)
+
+var (
+	badgeCritical = color.RGBA{0xE5, 0x39, 0x35, 0xFF}
+	badgeSevere   = color.RGBA{0xFB, 0x8C, 0x00, 0xFF}
+	badgeMajor    = color.RGBA{0xF9, 0xA8, 0x25, 0xFF}
+	badgeMinor    = color.RGBA{0x1E, 0x88, 0xE5, 0xFF}
+	badgePaused   = color.RGBA{0x75, 0x75, 0x75, 0xFF}
+	badgeError    = color.RGBA{0xB7, 0x1C, 0x1C, 0xFF}
+	badgeAuth     = color.RGBA{0x6A, 0x1B, 0x9A, 0xFF}
+	badgeText     = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+)
+
+// trayIconState is everything the tray icon depends on; the icon is only
+// re-rendered when it changes.
+type trayIconState struct {
+	mode     string
+	count    int
+	severity string
+}
+
+// trayState derives the icon state and tooltip from the monitor. In the
+// unread badge mode the badge counts and colours only the unread bugs.
+func trayState(stats monitor.Stats, bugs []monitor.Bug, health monitor.Health, badgeMode string) (trayIconState, string) {
+	switch {
+	case !health.Monitoring:
+		return trayIconState{mode: trayModePaused}, "禅道监控 - 已暂停"
+	case health.ErrorKind == "auth":
+		return trayIconState{mode: trayModeAuth}, "禅道监控 - 登录已过期，请更新 Cookie"
+	case health.Status == "error":
+		return trayIconState{mode: trayModeError}, "禅道监控 - 同步失败：" + truncateRunes(health.LastError, 60)
+	}
+	state := trayIconState{mode: trayModeNormal, count: stats.Total, severity: worstSeverity(stats.Severity)}
+	if badgeMode == badgeUnread {
+		state.count, state.severity = stats.Unread, worstSeverity(unreadSeverity(bugs))
+	}
+	if stats.LastUpdated.IsZero() {
+		return state, "禅道监控"
+	}
+	tooltip := fmt.Sprintf("禅道监控 - %d 个缺陷（一级 %d / 二级 %d / 三级 %d / 四级 %d）",
+		stats.Total, stats.Severity.Critical, stats.Severity.Severe, stats.Severity.Major, stats.Severity.Minor)
+	if stats.Muted > 0 {
+		tooltip += fmt.Sprintf("，已忽略 %d", stats.Muted)
+	}
+	if stats.Unread > 0 {
+		tooltip += fmt.Sprintf("，未读 %d", stats.Unread)
+	}
+	return state, tooltip
+}
+
+func unreadSeverity(bugs []monitor.Bug) monitor.SeverityCounts {
+	var counts monitor.SeverityCounts
+	for _, bug := range bugs {
+		if !bug.Unread {
+			continue
+		}
+		switch bug.Severity {
+		case "critical":
+			counts.Critical++
+		case "severe":
+			counts.Severe++
+		case "major":
+			counts.Major++
+		case "minor":
+			counts.Minor++
+		}
+	}
+	return counts
+}
+
+func worstSeverity(counts monitor.SeverityCounts) string {
+	switch {
+	case counts.Critical > 0:
+		return "critical"
+	case counts.Severe > 0:
+		return "severe"
+	case counts.Major > 0:
+		return "major"
+	case counts.Minor > 0:
+		return "minor"
+	}
+	return ""
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
+
+var (
+	trayBaseOnce sync.Once
+	trayBase     *image.RGBA
+)
+
+// trayBaseIcon is the app icon scaled down to trayIconSize.
+func trayBaseIcon() *image.RGBA {
+	trayBaseOnce.Do(func() {
+		src, err := png.Decode(bytes.NewReader(appIconPNG))
+		if err != nil {
+			trayBase = image.NewRGBA(image.Rect(0, 0, trayIconSize, trayIconSize))
+			return
+		}
+		trayBase = downscale(src, trayIconSize)
+	})
+	return trayBase
+}
+
+// downscale box-filters src into a size×size image.
+func downscale(src image.Image, size int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0 := b.Min.Y + y*b.Dy()/size
+		y1 := max(b.Min.Y+(y+1)*b.Dy()/size, y0+1)
+		for x := 0; x < size; x++ {
+			x0 := b.Min.X + x*b.Dx()/size
+			x1 := max(b.Min.X+(x+1)*b.Dx()/size, x0+1)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+cr, g+cg, bl+cb, a+ca, n+1
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{uint8(r / n >> 8), uint8(g / n >> 8), uint8(bl / n >> 8), uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+// renderTrayIcon draws the icon for state, encoded for the platform tray.
+func renderTrayIcon(state trayIconState) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, trayIconSize, trayIconSize))
+	draw.Draw(img, img.Bounds(), trayBaseIcon(), image.Point{}, draw.Src)
+
+	switch state.mode {
+	case trayModePaused:
+		greyscale(img)
+		drawBadge(img, string(glyphPause), badgePaused)
+	case trayModeError:
+		greyscale(img)
+		drawBadge(img, "!", badgeError)
+	case trayModeAuth:
+		drawBadge(img, "?", badgeAuth)
+	default:
+		if state.count > 0 {
+			label := strconv.Itoa(state.count)
+			if state.count > 99 {
+				label = "99+"
+			}
+			drawBadge(img, label, severityBadgeColor(state.severity))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return trayIconICO
+	}
+	if goRuntime.GOOS == "windows" {
+		return wrapPNGInICO(buf.Bytes(), trayIconSize)
+	}
+	return buf.Bytes()
+}
+
+func severityBadgeColor(severity string) color.RGBA {
+	switch severity {
+	case "critical":
+		return badgeCritical
+	case "severe":
+		return badgeSevere
+	case "major":
+		return badgeMajor
+	}
+	return badgeMinor
+}
+
+func greyscale(img *image.RGBA) {
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		r, g, b := uint32(img.Pix[i]), uint32(img.Pix[i+1]), uint32(img.Pix[i+2])
+		lum := uint8((r*299 + g*587 + b*114) / 1000)
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = lum, lum, lum
+	}
+}
+
+// badgeGlyphs is a 3×5 bitmap font, one row per string, '#' for ink.
+var badgeGlyphs = map[rune][5]string{
+	'0':        {"###", "#.#", "#.#", "#.#", "###"},
+	'1':        {".#.", "##.", ".#.", ".#.", "###"},
+	'2':        {"###", "..#", "###", "#..", "###"},
+	'3':        {"###", "..#", "###", "..#", "###"},
+	'4':        {"#.#", "#.#", "###", "..#", "..#"},
+	'5':        {"###", "#..", "###", "..#", "###"},
+	'6':        {"###", "#..", "###", "#.#", "###"},
+	'7':        {"###", "..#", "..#", "..#", "..#"},
+	'8':        {"###", "#.#", "###", "#.#", "###"},
+	'9':        {"###", "#.#", "###", "..#", "###"},
+	'+':        {"...", ".#.", "###", ".#.", "..."},
+	'!':        {".#.", ".#.", ".#.", "...", ".#."},
+	'?':        {"###", "..#", ".##", "...", ".#."},
+	glyphPause: {"#.#", "#.#", "#.#", "#.#", "#.#"},
+}
+
+// glyphPause draws the two pause bars.
+const glyphPause = '‖'
+
+// drawBadge paints label on a rounded pill in the bottom-right corner.
+func drawBadge(img *image.RGBA, label string, fill color.RGBA) {
+	runes := []rune(label)
+	scale := 4
+	if len(runes) > 2 {
+		scale = 3
+	}
+	textW := len(runes)*4*scale - scale
+	textH := 5 * scale
+	padX, padY := 2*scale, 2*scale
+	w := max(textW+2*padX, textH+2*padY)
+	h := textH + 2*padY
+	x0 := trayIconSize - w
+	y0 := trayIconSize - h
+	radius := h / 2
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if insideRoundedRect(x, y, w, h, radius) {
+				img.SetRGBA(x0+x, y0+y, fill)
+			}
+		}
+	}
+	tx := x0 + (w-textW)/2
+	ty := y0 + padY
+	for i, r := range runes {
+		glyph, ok := badgeGlyphs[r]
+		if !ok {
+			continue
+		}
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				px := tx + i*4*scale + col*scale
+				py := ty + row*scale
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), &image.Uniform{C: badgeText}, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+func insideRoundedRect(x, y, w, h, r int) bool {
+	cx := min(max(x, r), w-1-r)
+	cy := min(max(y, r), h-1-r)
+	dx, dy := x-cx, y-cy
+	return dx*dx+dy*dy <= r*r
+}
+
+// wrapPNGInICO wraps a PNG in a single-image ICO container, which the
+// Windows tray requires and which Windows Vista and later accept.
+func wrapPNGInICO(pngData []byte, size int) []byte {
+	var buf bytes.Buffer
+	dim := byte(size)
+	if size >= 256 {
+		dim = 0
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, [3]uint16{0, 1, 1})
+	buf.Write([]byte{dim, dim, 0, 0})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint16{1, 32})
+	_ = binary.Write(&buf, binary.LittleEndian, [2]uint32{uint32(len(pngData)), 6 + 16})
+	buf.Write(pngData)
+	return buf.Bytes()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"bugDog_V2/monitor"
+
+	"github.com/getlantern/systray"
+)
+
+// trayRecentBugs is how many of the newest bugs the tray lists.
+const trayRecentBugs = 10
+
+// trayMenu holds the tray items that change with the data. systray cannot
+// remove items, so the recent-bug slots are allocated up front and hidden
+// when unused.
+type trayMenu struct {
+	mu         sync.Mutex
+	levels     [4]*systray.MenuItem
+	recent     *systray.MenuItem
+	recentBugs []*systray.MenuItem
+	recentURLs []string
+	recentIDs  []int
+	lastSync   *systray.MenuItem
+	pause      *systray.MenuItem
+	snooze     *systray.MenuItem
+	snoozeOff  *systray.MenuItem
+}
+
+var trayLevelNames = [4]string{"一级", "二级", "三级", "四级"}
+
+func (a *App) buildTrayMenu() *trayMenu {
+	menu := &trayMenu{}
+	toggleItem := systray.AddMenuItem("显示/隐藏", "切换主窗口")
+	syncItem := systray.AddMenuItem("立即同步", "抓取最新缺陷")
+	menu.lastSync = systray.AddMenuItem("上次同步：—", "最近一次同步的时间和结果")
+	menu.lastSync.Disable()
+	systray.AddSeparator()
+
+	for i, name := range trayLevelNames {
+		menu.levels[i] = systray.AddMenuItem(name+"：0", "")
+		menu.levels[i].Disable()
+	}
+	menu.recent = systray.AddMenuItem("最近缺陷", "点击在浏览器中打开")
+	menu.recentURLs = make([]string, trayRecentBugs)
+	menu.recentIDs = make([]int, trayRecentBugs)
+	for i := 0; i < trayRecentBugs; i++ {
+		item := menu.recent.AddSubMenuItem("", "")
+		item.Hide()
+		menu.recentBugs = append(menu.recentBugs, item)
+		go a.watchRecentBug(menu, i, item)
+	}
+	systray.AddSeparator()
+
+	menu.pause = systray.AddMenuItemCheckbox("暂停监控", "暂停或恢复自动同步", false)
+	menu.snooze = systray.AddMenuItem("暂停通知", "同步照常进行，只是不弹通知和声音")
+	snooze30 := menu.snooze.AddSubMenuItem("30 分钟", "")
+	snooze60 := menu.snooze.AddSubMenuItem("1 小时", "")
+	snoozeTomorrow := menu.snooze.AddSubMenuItem("直到明天", "")
+	menu.snoozeOff = menu.snooze.AddSubMenuItem("恢复通知", "")
+	systray.AddSeparator()
+	quitItem := systray.AddMenuItem("退出", "退出应用")
+
+	go func() {
+		for {
+			select {
+			case <-toggleItem.ClickedCh:
+				a.toggleWindow()
+			case <-syncItem.ClickedCh:
+				a.monitor.TriggerSync()
+			case <-menu.pause.ClickedCh:
+				a.monitor.SetMonitoring(!a.monitor.IsMonitoring())
+			case <-snooze30.ClickedCh:
+				a.monitor.SnoozeNotifications(time.Now().Add(30 * time.Minute))
+			case <-snooze60.ClickedCh:
+				a.monitor.SnoozeNotifications(time.Now().Add(time.Hour))
+			case <-snoozeTomorrow.ClickedCh:
+				now := time.Now()
+				a.monitor.SnoozeNotifications(time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()))
+			case <-menu.snoozeOff.ClickedCh:
+				a.monitor.SnoozeNotifications(time.Time{})
+			case <-quitItem.ClickedCh:
+				a.quitFromTray()
+				return
+			}
+		}
+	}()
+	return menu
+}
+
+func (a *App) watchRecentBug(menu *trayMenu, slot int, item *systray.MenuItem) {
+	for range item.ClickedCh {
+		menu.mu.Lock()
+		url, id := menu.recentURLs[slot], menu.recentIDs[slot]
+		menu.mu.Unlock()
+		if url != "" {
+			_ = a.monitor.MarkBugSeen(id)
+			_ = a.OpenURL(url)
+		}
+	}
+}
+
+// refresh rewrites every data-driven item.
+func (menu *trayMenu) refresh(stats monitor.Stats, bugs []monitor.Bug, health monitor.Health) {
+	menu.mu.Lock()
+	defer menu.mu.Unlock()
+
+	counts := [4]int{stats.Severity.Critical, stats.Severity.Severe, stats.Severity.Major, stats.Severity.Minor}
+	for i, item := range menu.levels {
+		item.SetTitle(fmt.Sprintf("%s：%d", trayLevelNames[i], counts[i]))
+	}
+
+	newest := append([]monitor.Bug(nil), bugs...)
+	sort.SliceStable(newest, func(i, j int) bool { return newest[i].ID > newest[j].ID })
+	for i, item := range menu.recentBugs {
+		if i >= len(newest) {
+			menu.recentURLs[i] = ""
+			menu.recentIDs[i] = 0
+			item.Hide()
+			continue
+		}
+		bug := newest[i]
+		menu.recentURLs[i] = bug.URL
+		menu.recentIDs[i] = bug.ID
+		item.SetTitle(trayBugTitle(bug))
+		item.SetTooltip(bug.Title)
+		item.Show()
+	}
+	if len(newest) == 0 {
+		menu.recent.SetTitle("最近缺陷（无）")
+		menu.recent.Disable()
+	} else {
+		menu.recent.SetTitle("最近缺陷")
+		menu.recent.Enable()
+	}
+
+	menu.lastSync.SetTitle(trayLastSyncTitle(health))
+
+	if health.Monitoring {
+		menu.pause.Uncheck()
+	} else {
+		menu.pause.Check()
+	}
+	if health.SnoozedUntil.IsZero() {
+		menu.snooze.SetTitle("暂停通知")
+		menu.snoozeOff.Hide()
+	} else {
+		menu.snooze.SetTitle("通知已暂停至 " + health.SnoozedUntil.Format("01-02 15:04"))
+		menu.snoozeOff.Show()
+	}
+}
+
+// trayBugTitle labels a recent bug, with a dot in front while it is unread.
+func trayBugTitle(bug monitor.Bug) string {
+	title := truncateRunes(bug.Title, 36)
+	if level := severityLabel(bug.Severity); level != "" {
+		title = fmt.Sprintf("[%s] %s", level, title)
+	}
+	if bug.Unread {
+		return fmt.Sprintf("● #%d %s", bug.ID, title)
+	}
+	return fmt.Sprintf("#%d %s", bug.ID, title)
+}
+
+func severityLabel(severity string) string {
+	switch severity {
+	case "critical":
+		return "一级"
+	case "severe":
+		return "二级"
+	case "major":
+		return "三级"
+	case "minor":
+		return "四级"
+	}
+	return ""
+}
+
+func trayLastSyncTitle(health monitor.Health) string {
+	if health.LastAttempt.IsZero() {
+		return "上次同步：—"
+	}
+	status := "成功"
+	switch {
+	case health.ErrorKind == "auth":
+		status = "登录已过期"
+	case health.Status == "error":
+		status = "失败"
+	}
+	return fmt.Sprintf("上次同步：%s（%s）", health.LastAttempt.Format("15:04"), status)
+}
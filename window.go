@@ -0,0 +1,132 @@
+package main
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const windowFileName = "window.json"
+
+// WindowScreen identifies the monitor the window was on. Wails gives
+// screens no stable ID, so they are matched by list position and size.
+type WindowScreen struct {
+	Index   int  `json:"index"`
+	Width   int  `json:"width"`
+	Height  int  `json:"height"`
+	Primary bool `json:"primary"`
+}
+
+// WindowState is the main window geometry kept in window.json. X and Y are
+// relative to Screen, because Wails reports and applies positions relative
+// to the monitor the window is on.
+type WindowState struct {
+	X         int          `json:"x"`
+	Y         int          `json:"y"`
+	Width     int          `json:"width"`
+	Height    int          `json:"height"`
+	Maximised bool         `json:"maximised"`
+	Screen    WindowScreen `json:"screen"`
+}
+
+// loadWindowState returns the saved window geometry; ok is false when none
+// has been saved yet.
+func (a *App) loadWindowState() (state WindowState, ok bool) {
+	if err := a.monitor.LoadFile(windowFileName, &state); err != nil {
+		return WindowState{}, false
+	}
+	return state, state.Width > 0 && state.Height > 0
+}
+
+// saveWindowState records the window geometry before the window is hidden
+// or closed. A minimised window reports a meaningless position, and a
+// maximised one keeps the size it will return to, so neither overwrites the
+// saved bounds.
+func (a *App) saveWindowState() {
+	if a.ctx == nil || runtime.WindowIsMinimised(a.ctx) {
+		return
+	}
+	a.mu.Lock()
+	hidden := a.windowHidden
+	a.mu.Unlock()
+	if hidden {
+		return
+	}
+	state, _ := a.loadWindowState()
+	state.Maximised = runtime.WindowIsMaximised(a.ctx)
+	if !state.Maximised {
+		state.Width, state.Height = runtime.WindowGetSize(a.ctx)
+		state.X, state.Y = runtime.WindowGetPosition(a.ctx)
+	}
+	if screens, err := runtime.ScreenGetAll(a.ctx); err == nil {
+		for i, screen := range screens {
+			if screen.IsCurrent {
+				state.Screen = WindowScreen{
+					Index:   i,
+					Width:   screen.Size.Width,
+					Height:  screen.Size.Height,
+					Primary: screen.IsPrimary,
+				}
+				break
+			}
+		}
+	}
+	if err := a.monitor.SaveFile(windowFileName, state); err != nil {
+		a.monitor.Log("error", "Saving window geometry failed: "+err.Error())
+	}
+}
+
+// restoreWindowState applies the saved geometry, falling back to half the
+// primary screen when nothing is saved. While the saved monitor is still
+// connected the saved position is clamped to its size. When it is gone the
+// window is sized to fit the primary screen and centred.
+func (a *App) restoreWindowState() {
+	state, ok := a.loadWindowState()
+	if !ok {
+		a.resizeToScreen()
+		return
+	}
+	screens, err := runtime.ScreenGetAll(a.ctx)
+	if err != nil || len(screens) == 0 {
+		return
+	}
+	screen, found := matchScreen(screens, state.Screen)
+	width := min(state.Width, screen.Size.Width)
+	height := min(state.Height, screen.Size.Height)
+	runtime.WindowSetSize(a.ctx, width, height)
+	if found {
+		x := min(max(state.X, 0), screen.Size.Width-width)
+		y := min(max(state.Y, 0), screen.Size.Height-height)
+		runtime.WindowSetPosition(a.ctx, x, y)
+	} else {
+		runtime.WindowCenter(a.ctx)
+	}
+	if state.Maximised {
+		runtime.WindowMaximise(a.ctx)
+	}
+}
+
+// matchScreen finds the saved monitor: the same slot with the same size,
+// else any monitor of that size and role. Without a match it returns the
+// primary screen and false.
+func matchScreen(screens []runtime.Screen, saved WindowScreen) (runtime.Screen, bool) {
+	sameSize := func(screen runtime.Screen) bool {
+		return screen.Size.Width == saved.Width && screen.Size.Height == saved.Height
+	}
+	if saved.Index < len(screens) && sameSize(screens[saved.Index]) {
+		return screens[saved.Index], true
+	}
+	for _, screen := range screens {
+		if sameSize(screen) && screen.IsPrimary == saved.Primary {
+			return screen, true
+		}
+	}
+	return primaryScreen(screens), false
+}
+
+func primaryScreen(screens []runtime.Screen) runtime.Screen {
+	for _, screen := range screens {
+		if screen.IsPrimary {
+			return screen
+		}
+	}
+	return screens[0]
+}